@@ -0,0 +1,91 @@
+package main
+
+import (
+	"effictiveMobile/migrations"
+	"effictiveMobile/pkg/config"
+	"effictiveMobile/pkg/database"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const migrateUsage = `usage:
+  song_server [flags] migrate up         apply all new migrations
+  song_server [flags] migrate down [N]   roll back N migrations (1 by default)
+  song_server [flags] migrate goto N     migrate up or down to version N
+  song_server [flags] migrate version    print the current schema version
+  song_server [flags] migrate force N    set the version without running migrations, clearing the dirty flag`
+
+// errMigrateUsage сообщает о неверных аргументах команды migrate.
+var errMigrateUsage = errors.New(migrateUsage)
+
+// runMigrate выполняет команду migrate над базой из database.uri и печатает итоговую версию схемы.
+func runMigrate(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+	if cfg.DatabaseURI() == "" {
+		return errors.New("database.uri is required")
+	}
+
+	var run func(m *database.Migrator) error
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		run = (*database.Migrator).Up
+	case command == "down" && len(args) <= 2:
+		n := 1
+		if len(args) == 2 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("down: %q is not a positive number", args[1])
+			}
+			n = parsed
+		}
+		run = func(m *database.Migrator) error { return m.Down(n) }
+	case command == "goto" && len(args) == 2:
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("goto: %q is not a version number", args[1])
+		}
+		run = func(m *database.Migrator) error { return m.Goto(uint(version)) }
+	case command == "force" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return fmt.Errorf("force: %q is not a version number", args[1])
+		}
+		run = func(m *database.Migrator) error { return m.Force(version) }
+	case command == "version" && len(args) == 1:
+	default:
+		return errMigrateUsage
+	}
+
+	m, err := database.NewMigrator(migrations.FS, cfg.DatabaseURI())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if run != nil {
+		if err := run(m); errors.Is(err, database.ErrNoChange) {
+			fmt.Fprintln(out, "no change")
+		} else if err != nil {
+			return err
+		}
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	latest, err := m.Latest()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "version %d (latest %d)", version, latest)
+	if dirty {
+		fmt.Fprint(out, ", dirty")
+	}
+	fmt.Fprintln(out)
+	return nil
+}
@@ -1,6 +1,20 @@
 package main
 
-import "effictiveMobile/internal/application"
+import (
+	"effictiveMobile/internal/application"
+	"effictiveMobile/pkg/config"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `usage:
+  song_server [flags]               start the server
+  song_server [flags] config print  print the effective config with secrets redacted
+  song_server [flags] migrate ...   manage the database schema, see "song_server migrate"
+
+Run "song_server -h" to list flags.`
 
 // @Version 1.0.0
 // @Title Song library service
@@ -14,6 +28,42 @@ import "effictiveMobile/internal/application"
 // @Server http://localhost:8001 Server-1
 // @Security Authorization read write
 // @SecurityScheme Authorization apiKey header Authorization
+// @SecurityScheme Bearer http bearer JWT issued by the configured OIDC provider
 func main() {
-	application.Run()
+	store, args, err := config.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	switch {
+	case len(args) == 0:
+		if err := store.Current().Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid configuration:")
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if err := application.Run(store); err != nil {
+			os.Exit(1)
+		}
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		if err := store.Current().Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case args[0] == "migrate":
+		if err := runMigrate(store.Current(), args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			if errors.Is(err, errMigrateUsage) {
+				os.Exit(2)
+			}
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
 }
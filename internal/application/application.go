@@ -2,16 +2,23 @@ package application
 
 import (
 	"context"
+	"effictiveMobile/internal/domain/auth"
 	"effictiveMobile/internal/domain/service"
 	"effictiveMobile/internal/infrastrtucture/external_api"
 	"effictiveMobile/internal/infrastrtucture/http_controller"
+	"effictiveMobile/internal/infrastrtucture/jwt_verifier"
+	"effictiveMobile/internal/infrastrtucture/metrics"
 	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/internal/infrastrtucture/ratelimit"
+	"effictiveMobile/internal/infrastrtucture/tracing"
+	"effictiveMobile/migrations"
 	"effictiveMobile/pkg/config"
 	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/logging"
 	"fmt"
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
-	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -19,19 +26,60 @@ import (
 	"time"
 )
 
-func Run() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
-		AddSource: false,
-	}))
+// Run запускает сервис с конфигурацией из store и работает до SIGINT/SIGTERM; настройки,
+// поддерживающие перезагрузку, читаются из актуального снимка store.
+// Возвращает ошибку, если сервис не удалось запустить или сервер остановился из-за сбоя;
+// причина к этому моменту уже записана в лог.
+func Run(store *config.Store) error {
+	cfg := store.Current()
+
+	// Логгер и провайдер трасс принадлежат этому вызову Run и передаются компонентам явно,
+	// а не через slog.SetDefault и глобальные переменные otel.
+	logger, logLevels := logging.New(os.Stdout, loggingOptions(cfg))
 	logger.Info("Starting application")
 
-	DBURI := config.Config.DatabaseURI()
+	// Сигнал во время старта прерывает ожидание базы; после старта — запускает остановку.
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	tracer, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.TracingExporter(),
+		Endpoint:    cfg.TracingEndpoint(),
+		Insecure:    cfg.TracingInsecure(),
+		ServiceName: cfg.TracingServiceName(),
+		SampleRatio: cfg.TracingSampleRatio(),
+	})
+	if err != nil {
+		logger.Error("error initializing tracing", "error", err)
+		return err
+	}
+	defer func() {
+		// оставшиеся спаны отправляются после остановки сервера
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			logger.Error("error flushing traces", "error", err)
+		}
+	}()
+	// baseCtx — родительский контекст HTTP-запросов и фоновых задач, через него они получают провайдер трасс.
+	baseCtx := tracer.WithContext(context.Background())
+
+	DBURI := cfg.DatabaseURI()
 
-	db, err := database.Init(DBURI)
+	db, err := database.Init(ctx, DBURI, database.Options{
+		MaxConns:          int32(cfg.DatabaseMaxConns()),
+		MinConns:          int32(cfg.DatabaseMinConns()),
+		MaxConnLifetime:   cfg.DatabaseMaxConnLifetime(),
+		MaxConnIdleTime:   cfg.DatabaseMaxConnIdleTime(),
+		HealthCheckPeriod: cfg.DatabaseHealthCheckPeriod(),
+		ReconnectInterval: cfg.DatabaseReconnectInterval(),
+		ConnectTimeout:    cfg.DatabaseConnectTimeout(),
+		Migrations:        migrations.FS,
+		AutoMigrate:       cfg.DatabaseAutoMigrate(),
+	}, logger)
 	if err != nil {
 		logger.Error("error initializing database", "error", err, "dbURL", DBURI)
-		os.Exit(1)
+		return err
 	}
 	defer func() {
 		if err := db.Close(); err != nil {
@@ -41,29 +89,130 @@ func Run() {
 
 	logger.Info("init database")
 
+	appMetrics := metrics.New(db)
+
 	// init repository
 	songRepo := persistence.NewSongRepository(db, logger)
+	songChangeRepo := persistence.NewSongChangeRepository(db, logger)
+	apiKeyRepo := persistence.NewApiKeyRepository(db, logger)
+	auditRepo := persistence.NewAuditRepository(db, logger)
+	userRepo := persistence.NewUserRepository(db, logger)
 
 	// init services
-	apiCli := external_api.NewClient()
-	songService := service.NewSongService(songRepo, logger, apiCli)
+	apiCli := external_api.NewClient(store, appMetrics.ObserveExternalCall)
+	auditService := service.NewAuditService(auditRepo, logger)
+	songService := service.NewSongService(songRepo, songChangeRepo, db, auditService, appMetrics, logger, apiCli)
+	apiKeyService := service.NewApiKeyService(apiKeyRepo, db, auditService, logger)
 
 	// init controllers
 	songController := http_controller.NewSongController(songService, logger)
+	apiKeyController := http_controller.NewApiKeyController(apiKeyService, logger)
+	auditController := http_controller.NewAuditController(auditService, logger)
+	logController := http_controller.NewLogController(logLevels, logger)
+	healthController := http_controller.NewHealthController(readinessChecks(db, apiCli), logger)
+
+	var tokenVerifiers auth.Verifiers
+	var userController *http_controller.UserController
+	if signingKey := cfg.UserTokenSigningKey(); signingKey != "" {
+		issuer := jwt_verifier.NewLocalIssuer([]byte(signingKey), cfg.UserTokenIssuer(), cfg.UserTokenTTL())
+		userService := service.NewUserService(userRepo, issuer, db, auditService, logger)
+		userController = http_controller.NewUserController(userService, logger)
+		tokenVerifiers = append(tokenVerifiers, issuer)
+	}
+
+	if cfg.JWTEnabled() {
+		verifier, err := jwt_verifier.NewVerifier(ctx, jwt_verifier.Options{
+			JWKSURL:         cfg.JWKSURL(),
+			JWKSFile:        cfg.JWKSFile(),
+			Issuer:          cfg.JWTIssuer(),
+			Audience:        cfg.JWTAudience(),
+			ScopeClaim:      cfg.JWTScopeClaim(),
+			UserIDClaim:     cfg.JWTUserIDClaim(),
+			RefreshInterval: cfg.JWKSRefreshInterval(),
+		}, logger)
+		if err != nil {
+			logger.Error("error initializing jwt verifier", "error", err)
+			return err
+		}
+		tokenVerifiers = append(tokenVerifiers, verifier)
+	}
+
+	var tokenVerifier auth.TokenVerifier
+	if len(tokenVerifiers) > 0 {
+		tokenVerifier = tokenVerifiers
+	}
+
+	var hmacAuthenticator *http_controller.HMACAuthenticator
+	if clients := cfg.HMACClients(); len(clients) > 0 {
+		hmacAuthenticator = http_controller.NewHMACAuthenticator(clients, cfg.HMACMaxSkew(), cfg.HMACNonceCacheSize())
+	}
+	authenticator := http_controller.NewAuthenticator(apiKeyService, tokenVerifier, hmacAuthenticator, store, logger)
+
+	var rateLimiter *http_controller.RateLimiter
+	var pgLimiterStore *ratelimit.PostgresStore
+	if cfg.RateLimitEnabled() {
+		var limiterStore ratelimit.Store = ratelimit.NewMemoryStore()
+		if cfg.RateLimitStore() == "postgres" {
+			pgLimiterStore = ratelimit.NewPostgresStore(db, logger)
+			limiterStore = pgLimiterStore
+		}
+		rateLimiter = http_controller.NewRateLimiter(limiterStore, cfg.RateLimitQuota, logger)
+	}
+
+	// protect проверяет скоуп маршрута и ограничивает частоту запросов по его квоте
+	protect := func(scope string, handler http.HandlerFunc) http.Handler {
+		return rateLimiter.Limit(scope, http_controller.RequireScope(scope, handler))
+	}
+
+	requestLogger := http_controller.NewRequestLogger(logger)
+	requestDeadline := http_controller.NewRequestDeadline(store)
 
 	r := mux.NewRouter()
+	r.Use(appMetrics.Middleware)
+
+	r.Handle("/metrics", appMetrics.Handler()).Methods("GET")
+
+	// probes for orchestrators and load balancers
+	r.HandleFunc("/healthz", healthController.LivenessHandler).Methods("GET")
+	r.HandleFunc("/readyz", healthController.ReadinessHandler).Methods("GET")
 
 	// add subprefix to routes
 	route := r.PathPrefix("/api/v1").Subrouter()
+	route.Use(http_controller.Tracing, http_controller.RequestContext, requestLogger.Handle, requestDeadline.Handle)
 
 	// init routes for songs
 	songsRouter := route.PathPrefix("/songs").Subrouter()
-	songsRouter.Use(http_controller.Auth)
-	songsRouter.HandleFunc("", songController.GetSongsHandler).Methods("GET")
-	songsRouter.HandleFunc("/{id:[0-9]+}", songController.GetSongByIDHandler).Methods("GET")
-	songsRouter.HandleFunc("/create", songController.CreateSongHandler).Methods("POST")
-	songsRouter.HandleFunc("/update/{id:[0-9]+}", songController.UpdateSongHandler).Methods("PUT")
-	songsRouter.HandleFunc("/delete/{id:[0-9]+}", songController.DeleteSongHandler).Methods("DELETE")
+	songsRouter.Use(authenticator.Auth)
+	songsRouter.Handle("", protect(auth.ScopeSongsRead, songController.GetSongsHandler)).Methods("GET")
+	songsRouter.Handle("/{id:[0-9]+}", protect(auth.ScopeSongsRead, songController.GetSongByIDHandler)).Methods("GET")
+	songsRouter.Handle("/create", protect(auth.ScopeSongsWrite, songController.CreateSongHandler)).Methods("POST")
+	songsRouter.Handle("/update/{id:[0-9]+}", protect(auth.ScopeSongsWrite, songController.UpdateSongHandler)).Methods("PUT")
+	songsRouter.Handle("/delete/{id:[0-9]+}", protect(auth.ScopeSongsDelete, songController.DeleteSongHandler)).Methods("DELETE")
+	songsRouter.Handle("/proposals", protect(auth.ScopeSongsRead, songController.GetChangeProposalsHandler)).Methods("GET")
+	songsRouter.Handle("/proposals/accept/{id:[0-9]+}", protect(auth.ScopeSongsWrite, songController.AcceptChangeProposalHandler)).Methods("POST")
+	songsRouter.Handle("/proposals/reject/{id:[0-9]+}", protect(auth.ScopeSongsWrite, songController.RejectChangeProposalHandler)).Methods("POST")
+
+	// init admin routes
+	adminRouter := route.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(authenticator.Auth)
+	adminRouter.Handle("/keys", protect(auth.ScopeAdmin, apiKeyController.GetApiKeysHandler)).Methods("GET")
+	adminRouter.Handle("/keys/create", protect(auth.ScopeAdmin, apiKeyController.CreateApiKeyHandler)).Methods("POST")
+	adminRouter.Handle("/keys/rotate/{id:[0-9]+}", protect(auth.ScopeAdmin, apiKeyController.RotateApiKeyHandler)).Methods("POST")
+	adminRouter.Handle("/keys/revoke/{id:[0-9]+}", protect(auth.ScopeAdmin, apiKeyController.RevokeApiKeyHandler)).Methods("DELETE")
+	adminRouter.Handle("/songs/re-enrich/{id:[0-9]+}", protect(auth.ScopeAdmin, songController.ReEnrichSongHandler)).Methods("POST")
+	adminRouter.Handle("/audit", protect(auth.ScopeAdmin, auditController.GetAuditEntriesHandler)).Methods("GET")
+	adminRouter.Handle("/log-level", protect(auth.ScopeAdmin, logController.GetLogLevelsHandler)).Methods("GET")
+	adminRouter.Handle("/log-level", protect(auth.ScopeAdmin, logController.SetLogLevelHandler)).Methods("PUT")
+	adminRouter.Handle("/log-level", protect(auth.ScopeAdmin, logController.ResetLogLevelHandler)).Methods("DELETE")
+
+	// init routes for users, available only when user tokens are configured
+	if userController != nil {
+		usersRouter := route.PathPrefix("/users").Subrouter()
+		usersRouter.Handle("/register", rateLimiter.Limit("", http.HandlerFunc(userController.RegisterHandler))).Methods("POST")
+		usersRouter.Handle("/login", rateLimiter.Limit("", http.HandlerFunc(userController.LoginHandler))).Methods("POST")
+
+		adminRouter.Handle("/users/role/{id:[0-9]+}", protect(auth.ScopeAdmin, userController.SetUserRoleHandler)).Methods("PUT")
+	}
 
 	// ping endpoint
 	route.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
@@ -77,51 +226,112 @@ func Run() {
 	}).Methods("GET")
 
 	// swagger UI endpoint
-	specUrl := config.Config.ServerURI() + "/api/v1/docs/spec"
+	specUrl := cfg.ServerURI() + "/api/v1/docs/spec"
 
 	route.PathPrefix("/docs/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL(specUrl),
 	))
 
 	// server config
-	address := fmt.Sprintf("%s:%s", config.Config.ServerHost(), config.Config.ServerPort())
+	address := fmt.Sprintf("%s:%s", cfg.ServerHost(), cfg.ServerPort())
 
 	server := http.Server{
-		Addr:         address,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		Handler:      route,
+		Addr:           address,
+		ReadTimeout:    cfg.ServerReadTimeout(),
+		WriteTimeout:   cfg.ServerWriteTimeout(),
+		IdleTimeout:    cfg.ServerIdleTimeout(),
+		MaxHeaderBytes: cfg.ServerMaxHeaderBytes(),
+		Handler:        r,
+		BaseContext:    func(net.Listener) context.Context { return baseCtx },
+	}
+
+	// Порт занимается до запуска фоновых задач: если он недоступен, сервис сразу завершается.
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Error("error listening", "address", address, "error", err)
+		return err
+	}
+
+	// background workers, stopped in reverse order
+	bg := newWorkers(baseCtx, logger)
+	bg.Go("db-supervisor", db.Supervise)
+	bg.Go("config-watcher", func(ctx context.Context) {
+		store.Watch(ctx, logger, func() {
+			current := store.Current()
+			logLevels.Configure(loggingOptions(current))
+			apiCli.SetRateLimit(current.ExternalRateLimit(), current.ExternalBurst())
+		})
+	})
+	if pgLimiterStore != nil {
+		bg.Go("rate-limit-cleanup", pgLimiterStore.Run)
+	}
+	if cfg.EnrichmentEnabled() {
+		scheduler := service.NewEnrichmentScheduler(
+			songService,
+			logger,
+			cfg.EnrichmentInterval(),
+			cfg.EnrichmentMaxAge(),
+			cfg.EnrichmentBatchSize(),
+		)
+		bg.Go("enrichment-scheduler", scheduler.Run)
 	}
 
 	logger.Info("starting server", "address", address)
 
+	serveErr := make(chan error, 1)
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("listen", "address", address, "error", err)
-		}
+		serveErr <- server.Serve(listener)
 	}()
 
-	quit := make(chan os.Signal)
-	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
+	var runErr error
 	select {
-	case d := <-quit:
-		logger.Info("servers stopped", "reason", d.String())
+	case <-ctx.Done():
+		logger.Info("shutdown signal received")
+	case err := <-serveErr:
+		logger.Error("server stopped unexpectedly", "error", err)
+		runErr = err
 	}
+	stopSignals()
 
 	logger.Info("Shutdown Server ...")
+	if runErr == nil {
+		healthController.SetDraining()
+		if delay := cfg.ServerDrainDelay(); delay > 0 {
+			logger.Info("waiting for load balancers to drain", "delay", delay)
+			time.Sleep(delay)
+		}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Сначала дожидаемся активных запросов, потом останавливаем задачи, от которых они зависят.
+	shutdownTimeout := cfg.ServerShutdownTimeout()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server Shutdown:", err)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("in-flight requests did not finish in time, closing connections", "timeout", shutdownTimeout, "error", err)
+		server.Close()
 	}
 
-	select {
-	case <-ctx.Done():
-		logger.Warn("timeout of 5 seconds.")
-	}
+	workersCtx, cancelWorkers := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelWorkers()
+	bg.Stop(workersCtx)
 
 	logger.Info("Server exiting")
+	return runErr
+}
+
+// loggingOptions собирает настройки логгера из конфигурации.
+func loggingOptions(cfg *config.Config) logging.Options {
+	initial, thereafter, interval := cfg.LogSampling()
+	return logging.Options{
+		Format:    cfg.LogFormat(),
+		AddSource: cfg.LogAddSource(),
+		Level:     cfg.LogLevel(),
+		Packages:  cfg.LogPackageLevels(),
+		Sampling: logging.Sampling{
+			Initial:    initial,
+			Thereafter: thereafter,
+			Interval:   interval,
+		},
+	}
 }
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"effictiveMobile/pkg/config"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+// loadTestConfig записывает конфиг во временный файл и загружает его через config.Load,
+// как это сделал бы сервис, встраивающий Run.
+func loadTestConfig(t *testing.T, body string) *config.Store {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return config.NewStore(cfg)
+}
+
+// Базы нет, поэтому оба Run завершаются на подключении к ней, но к этому моменту
+// логгер и провайдер трасс уже созданы, и глобальные значения не должны измениться.
+func TestRunDoesNotReplaceProcessGlobals(t *testing.T) {
+	stores := []*config.Store{
+		loadTestConfig(t, `{
+			"database": {"uri": "postgres://songlib@127.0.0.1:1/first?sslmode=disable", "connect_timeout": "200ms"},
+			"server": {"port": "0"},
+			"log": {"format": "json", "level": "debug"},
+			"tracing": {"exporter": "stdout", "service_name": "first"}
+		}`),
+		loadTestConfig(t, `{
+			"database": {"uri": "postgres://songlib@127.0.0.1:1/second?sslmode=disable", "connect_timeout": "200ms"},
+			"server": {"port": "0"},
+			"log": {"format": "text", "level": "error"},
+			"tracing": {"exporter": "none", "service_name": "second"}
+		}`),
+	}
+
+	defaultLogger := slog.Default()
+	tracerProvider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(stores))
+	for i, store := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = Run(store)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("Run #%d: expected a database error", i)
+		}
+	}
+	if slog.Default() != defaultLogger {
+		t.Error("Run must not replace the default slog logger")
+	}
+	if otel.GetTracerProvider() != tracerProvider {
+		t.Error("Run must not replace the global tracer provider")
+	}
+	if otel.GetTextMapPropagator() != propagator {
+		t.Error("Run must not replace the global text map propagator")
+	}
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+	"effictiveMobile/internal/infrastrtucture/external_api"
+	"effictiveMobile/internal/infrastrtucture/http_controller"
+	"effictiveMobile/pkg/database"
+	"errors"
+	"fmt"
+)
+
+// readinessChecks описывает зависимости, от которых зависит готовность сервиса.
+// Внешний API некритичен: без него не работает только обогащение песен.
+func readinessChecks(db *database.DB, apiCli *external_api.Client) []http_controller.DependencyCheck {
+	return []http_controller.DependencyCheck{
+		{
+			Name:     "database",
+			Critical: true,
+			Check: func(ctx context.Context) (string, error) {
+				return "", db.Ping(ctx)
+			},
+		},
+		{
+			Name:     "migrations",
+			Critical: true,
+			Check: func(ctx context.Context) (string, error) {
+				version, dirty, err := db.MigrationVersion(ctx)
+				if err != nil {
+					return "", err
+				}
+				detail := fmt.Sprintf("version %d", version)
+				if dirty {
+					return detail, errors.New("schema is dirty, a migration failed halfway")
+				}
+				if version < db.SchemaVersion() {
+					return detail, fmt.Errorf("schema is older than version %d the service started with", db.SchemaVersion())
+				}
+				return detail, nil
+			},
+		},
+		{
+			Name:     "external_api",
+			Critical: false,
+			Check: func(ctx context.Context) (string, error) {
+				state := apiCli.CircuitState()
+				if state == external_api.CircuitOpen {
+					return "circuit " + string(state), external_api.ErrCircuitOpen
+				}
+				return "circuit " + string(state), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+)
+
+// workers запускает фоновые задачи и останавливает их в порядке, обратном запуску:
+// задачи, запущенные позже, могут зависеть от запущенных раньше (планировщик — от пула базы).
+type workers struct {
+	base    context.Context
+	logger  *slog.Logger
+	running []*worker
+}
+
+type worker struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newWorkers создаёт набор задач, контексты которых наследуют значения base (например, провайдер трасс).
+func newWorkers(base context.Context, logger *slog.Logger) *workers {
+	return &workers{base: base, logger: logger.With("component", "Workers")}
+}
+
+// Go запускает run в отдельной горутине; run должен вернуться после отмены ctx.
+func (w *workers) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(w.base)
+	wk := &worker{name: name, cancel: cancel, done: make(chan struct{})}
+	w.running = append(w.running, wk)
+
+	go func() {
+		defer close(wk.done)
+		run(ctx)
+	}()
+}
+
+// Stop по очереди отменяет задачи и ждёт завершения каждой, но не дольше ctx.
+func (w *workers) Stop(ctx context.Context) {
+	for i := len(w.running) - 1; i >= 0; i-- {
+		wk := w.running[i]
+		wk.cancel()
+		select {
+		case <-wk.done:
+			w.logger.Info("worker stopped", "worker", wk.name)
+		case <-ctx.Done():
+			w.logger.Warn("worker did not stop in time", "worker", wk.name)
+		}
+	}
+	w.running = nil
+}
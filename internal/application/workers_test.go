@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkersStopInReverseOrder(t *testing.T) {
+	bg := newWorkers(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var mu sync.Mutex
+	var stopped []string
+	for _, name := range []string{"pool-supervisor", "config-watcher", "enrichment-scheduler"} {
+		bg.Go(name, func(ctx context.Context) {
+			<-ctx.Done()
+			// Задержка делает гонку заметной, если Stop не ждёт задачу перед отменой следующей.
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			stopped = append(stopped, name)
+			mu.Unlock()
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bg.Stop(ctx)
+
+	want := []string{"enrichment-scheduler", "config-watcher", "pool-supervisor"}
+	if !slices.Equal(stopped, want) {
+		t.Errorf("stop order = %v, want %v", stopped, want)
+	}
+}
+
+func TestWorkersStopGivesUpOnStuckWorker(t *testing.T) {
+	bg := newWorkers(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	firstStopped := make(chan struct{})
+	bg.Go("first", func(ctx context.Context) {
+		<-ctx.Done()
+		close(firstStopped)
+	})
+	release := make(chan struct{})
+	defer close(release)
+	bg.Go("stuck", func(context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	returned := make(chan struct{})
+	go func() {
+		bg.Stop(ctx)
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Stop must return once its context is done")
+	}
+	select {
+	case <-firstStopped:
+	case <-time.After(time.Second):
+		t.Error("workers started before a stuck one must still be cancelled")
+	}
+}
+
+func TestWorkersInheritBaseContextValues(t *testing.T) {
+	type key struct{}
+	base := context.WithValue(context.Background(), key{}, "tracer")
+	bg := newWorkers(base, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	got := make(chan any, 1)
+	bg.Go("probe", func(ctx context.Context) {
+		got <- ctx.Value(key{})
+		<-ctx.Done()
+	})
+	if v := <-got; v != "tracer" {
+		t.Errorf("worker context value = %v, want the base context value", v)
+	}
+	bg.Stop(context.Background())
+}
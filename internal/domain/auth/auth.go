@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+const (
+	ScopeSongsRead   = "songs:read"
+	ScopeSongsWrite  = "songs:write"
+	ScopeSongsDelete = "songs:delete"
+	ScopeAdmin       = "admin"
+)
+
+// Scopes перечисляет все известные скоупы.
+var Scopes = []string{ScopeSongsRead, ScopeSongsWrite, ScopeSongsDelete, ScopeAdmin}
+
+const (
+	PrincipalApiKey       = "api_key"
+	PrincipalBootstrapKey = "bootstrap_key"
+	PrincipalJWT          = "jwt"
+	PrincipalUser         = "user"
+	PrincipalHMAC         = "hmac"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// RoleScopes возвращает скоупы, которые даёт роль пользователя.
+func RoleScopes(role string) []string {
+	switch role {
+	case RoleViewer:
+		return []string{ScopeSongsRead}
+	case RoleEditor:
+		return []string{ScopeSongsRead, ScopeSongsWrite, ScopeSongsDelete}
+	case RoleAdmin:
+		return []string{ScopeAdmin}
+	}
+	return nil
+}
+
+// IsValidRole сообщает, известна ли роль.
+func IsValidRole(role string) bool {
+	return role == RoleViewer || role == RoleEditor || role == RoleAdmin
+}
+
+// Principal описывает аутентифицированного клиента, выполняющего запрос.
+type Principal struct {
+	Type   string
+	ID     string
+	Name   string
+	Scopes []string
+	// UserID заполняется, если запрос выполняется от имени пользователя, а не интеграции.
+	UserID string
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier проверяет bearer-токен и возвращает клиента, от имени которого выполняется запрос.
+// Для любого невалидного токена возвращается ошибка, обёрнутая вокруг ErrInvalidToken.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// HasScope проверяет наличие скоупа; admin даёт доступ ко всему.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// IsValidScope сообщает, известен ли скоуп.
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// Verifiers проверяет токен по очереди каждым верификатором и возвращает первый успешный результат.
+type Verifiers []TokenVerifier
+
+func (v Verifiers) Verify(ctx context.Context, token string) (*Principal, error) {
+	err := ErrInvalidToken
+	for _, verifier := range v {
+		var principal *Principal
+		principal, err = verifier.Verify(ctx, token)
+		if err == nil {
+			return principal, nil
+		}
+		if !errors.Is(err, ErrInvalidToken) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает клиента запроса или nil, если запрос не аутентифицирован.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+type ApiKey struct {
+	ID         int        `json:"id" example:"1" description:"API key ID"`
+	Name       string     `json:"name" example:"partner-app" description:"Human readable key name"`
+	Prefix     string     `json:"prefix" example:"sl_Ab12Cd" description:"First characters of the key, used to identify it"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes" example:"[\"songs:read\"]" description:"Granted scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2025-01-01T00:00:00Z" description:"Key expiration time"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-10-01T12:00:00Z" description:"Last successful authentication"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-10-01T12:00:00Z" description:"Key creation time"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2024-10-02T12:00:00Z" description:"Key revocation time"`
+}
+
+// IsActive сообщает, можно ли аутентифицироваться ключом в момент now.
+func (k *ApiKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" example:"partner-app" description:"Human readable key name"`
+	Scopes    []string   `json:"scopes" example:"[\"songs:read\"]" description:"Scopes to grant"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-01-01T00:00:00Z" description:"Optional expiration time"`
+}
+
+type RotateApiKeyRequest struct {
+	Overlap string `json:"overlap" example:"24h" description:"How long the old key keeps working, 24h by default"`
+}
+
+// IssuedApiKeyResponse возвращается при создании и ротации ключа; секрет больше нигде не показывается.
+type IssuedApiKeyResponse struct {
+	Key    ApiKey `json:"key"`
+	Secret string `json:"secret" example:"sl_5p9rG..." description:"Raw key, shown only once"`
+}
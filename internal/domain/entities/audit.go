@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+const (
+	AuditEntitySong   = "song"
+	AuditEntityApiKey = "api_key"
+	AuditEntityUser   = "user"
+)
+
+const (
+	AuditActionSongCreate   = "song.create"
+	AuditActionSongUpdate   = "song.update"
+	AuditActionSongDelete   = "song.delete"
+	AuditActionSongMerge    = "song.merge"
+	AuditActionSongRefresh  = "song.refresh"
+	AuditActionSongAccept   = "song.accept_change"
+	AuditActionSongReject   = "song.reject_change"
+	AuditActionApiKeyCreate = "api_key.create"
+	AuditActionApiKeyRotate = "api_key.rotate"
+	AuditActionApiKeyRevoke = "api_key.revoke"
+	AuditActionUserSetRole  = "user.set_role"
+)
+
+type AuditEntry struct {
+	ID         int64          `json:"id" example:"1" description:"Audit entry ID"`
+	ActorType  string         `json:"actor_type" example:"api_key" description:"Kind of the caller"`
+	ActorID    string         `json:"actor_id" example:"3" description:"Caller ID"`
+	ActorName  string         `json:"actor_name" example:"partner-app" description:"Caller name"`
+	Action     string         `json:"action" example:"song.update" description:"Performed action"`
+	EntityType string         `json:"entity_type" example:"song" description:"Kind of the changed entity"`
+	EntityID   string         `json:"entity_id" example:"4" description:"ID of the changed entity"`
+	BeforeHash string         `json:"before_hash,omitempty" example:"9f86d08..." description:"SHA-256 of the entity before the change"`
+	AfterHash  string         `json:"after_hash,omitempty" example:"60303ae..." description:"SHA-256 of the entity after the change"`
+	RequestID  string         `json:"request_id,omitempty" example:"4bf92f3577b34da6" description:"ID of the HTTP request"`
+	ClientIP   string         `json:"client_ip,omitempty" example:"10.0.0.7" description:"Address of the caller"`
+	Details    map[string]any `json:"details,omitempty" description:"Action specific details"`
+	CreatedAt  time.Time      `json:"created_at" example:"2024-10-01T12:00:00Z" description:"Time of the action"`
+}
+
+// AuditFilter задаёт условия выборки журнала аудита; пустые поля не фильтруют.
+type AuditFilter struct {
+	ActorType  string
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,20 @@
+package entities
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFail     = "fail"
+	HealthStatusDegraded = "degraded"
+)
+
+type DependencyHealth struct {
+	Status    string  `json:"status" example:"ok" description:"ok or fail"`
+	Critical  bool    `json:"critical" example:"true" description:"Whether a failure makes the service not ready"`
+	LatencyMs float64 `json:"latency_ms" example:"1.7" description:"Duration of the check"`
+	Detail    string  `json:"detail,omitempty" example:"version 8" description:"Check specific details"`
+	Error     string  `json:"error,omitempty" example:"can't ping postgres" description:"Why the check failed"`
+}
+
+type HealthResponse struct {
+	Status       string                      `json:"status" example:"ok" description:"ok, degraded or fail"`
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty" description:"Result per dependency"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+type SetLogLevelRequest struct {
+	Package  string `json:"package,omitempty" example:"persistence" description:"Package name or import path; empty changes the global level"`
+	Level    string `json:"level" example:"debug" description:"debug, info, warn or error"`
+	Duration string `json:"duration,omitempty" example:"15m" description:"How long the level stays in effect, 15m by default, at most 24h"`
+}
+
+type LogLevelOverride struct {
+	Package   string    `json:"package,omitempty" example:"persistence" description:"Empty for the global level"`
+	Level     string    `json:"level" example:"DEBUG"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-10-01T12:15:00Z"`
+}
+
+type LogLevelsResponse struct {
+	Level     string             `json:"level" example:"INFO" description:"Level for packages without an override"`
+	Packages  map[string]string  `json:"packages,omitempty" description:"Effective level per package"`
+	Overrides []LogLevelOverride `json:"overrides,omitempty" description:"Temporary levels set through the API"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// ProvenanceSourceManual обозначает значение, введённое редактором вручную.
+const ProvenanceSourceManual = "manual"
+
+// EnrichableSongFields перечисляет поля песни, которые заполняются из внешнего API.
+var EnrichableSongFields = []string{SongFieldReleaseDate, SongFieldText, SongFieldLink}
+
+// FieldProvenance описывает происхождение значения одного поля песни.
+type FieldProvenance struct {
+	Source             string     `json:"source" example:"external_api" description:"Provider the value came from, or manual"`
+	FetchedAt          *time.Time `json:"fetched_at,omitempty" example:"2024-10-01T12:00:00Z" description:"Last time the value was fetched from the provider"`
+	ManuallyOverridden bool       `json:"manually_overridden" example:"false" description:"Value was edited manually and is not overwritten by enrichment"`
+	UpdatedAt          time.Time  `json:"updated_at" example:"2024-10-01T12:00:00Z" description:"Last time the provenance changed"`
+}
+
+// SongFieldValue возвращает значение обогащаемого поля песни по его имени.
+func SongFieldValue(song *Song, field string) string {
+	switch field {
+	case SongFieldReleaseDate:
+		return song.ReleaseDate
+	case SongFieldText:
+		return song.Text
+	case SongFieldLink:
+		return song.Link
+	}
+	return ""
+}
+
+// SetSongFieldValue записывает значение обогащаемого поля песни по его имени.
+func SetSongFieldValue(song *Song, field, value string) {
+	switch field {
+	case SongFieldReleaseDate:
+		song.ReleaseDate = value
+	case SongFieldText:
+		song.Text = value
+	case SongFieldLink:
+		song.Link = value
+	}
+}
@@ -1,17 +1,34 @@
 package entities
 
+import "time"
+
 type Song struct {
-	ID          int    `json:"id" example:"1" description:"Song ID"`
-	Group       string `json:"group" example:"Muse" description:"Group or band name"`
-	Song        string `json:"song" example:"Supermassive Black Hole" description:"Song title"`
-	ReleaseDate string `json:"release_date" example:"2006-06-19" description:"Song release date"`
-	Text        string `json:"text" example:"Lyrics of the song" description:"Lyrics of the song"`
-	Link        string `json:"link" example:"http://example.com/song" description:"Link to the song"`
+	ID          int        `json:"id" example:"1" description:"Song ID"`
+	Group       string     `json:"group" example:"Muse" description:"Group or band name"`
+	Song        string     `json:"song" example:"Supermassive Black Hole" description:"Song title"`
+	ReleaseDate string     `json:"release_date" example:"2006-06-19" description:"Song release date"`
+	Text        string     `json:"text" example:"Lyrics of the song" description:"Lyrics of the song"`
+	Link        string     `json:"link" example:"http://example.com/song" description:"Link to the song"`
+	EnrichedAt  *time.Time `json:"enriched_at,omitempty" example:"2024-10-01T12:00:00Z" description:"Last time details were fetched from the external API"`
+	OwnerID     *int       `json:"owner_id,omitempty" example:"1" description:"ID of the user who owns the song"`
+	Visibility  string     `json:"visibility" example:"public" description:"private, shared or public"`
+
+	Provenance map[string]FieldProvenance `json:"provenance,omitempty" description:"Origin of release_date, text and link, returned in song details"`
 }
 
+const (
+	// VisibilityPrivate — песня видна только владельцу.
+	VisibilityPrivate = "private"
+	// VisibilityShared — песня видна всем зарегистрированным пользователям.
+	VisibilityShared = "shared"
+	// VisibilityPublic — песня видна всем клиентам, включая интеграции по API-ключу.
+	VisibilityPublic = "public"
+)
+
 type CreateSongRequest struct {
-	Group string `json:"group" example:"Muse" description:"Название группы"`
-	Song  string `json:"song" example:"Supermassive Black Hole" description:"Название песни"`
+	Group      string `json:"group" example:"Muse" description:"Название группы"`
+	Song       string `json:"song" example:"Supermassive Black Hole" description:"Название песни"`
+	Visibility string `json:"visibility,omitempty" example:"private" description:"private, shared или public (по умолчанию)"`
 }
 
 type SongsResponse struct {
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+const (
+	ProposalStatusPending  = "pending"
+	ProposalStatusAccepted = "accepted"
+	ProposalStatusRejected = "rejected"
+)
+
+// Поля песни, которые заполняются из внешнего API и могут обновляться при повторном обогащении.
+const (
+	SongFieldReleaseDate = "release_date"
+	SongFieldText        = "text"
+	SongFieldLink        = "link"
+)
+
+type SongChangeProposal struct {
+	ID            int        `json:"id" example:"1" description:"Proposal ID"`
+	SongID        int        `json:"song_id" example:"1" description:"ID of the song the change belongs to"`
+	Field         string     `json:"field" example:"text" description:"Changed field"`
+	CurrentValue  string     `json:"current_value" example:"Old lyrics" description:"Value stored at the time of the refresh"`
+	ProposedValue string     `json:"proposed_value" example:"New lyrics" description:"Value returned by the external API"`
+	Status        string     `json:"status" example:"pending" description:"pending, accepted or rejected"`
+	CreatedAt     time.Time  `json:"created_at" example:"2024-10-01T12:00:00Z" description:"Time the difference was detected"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty" example:"2024-10-02T12:00:00Z" description:"Time the proposal was accepted or rejected"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+type User struct {
+	ID           int       `json:"id" example:"1" description:"User ID"`
+	Username     string    `json:"username" example:"alice" description:"Login name"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role" example:"viewer" description:"viewer, editor or admin"`
+	CreatedAt    time.Time `json:"created_at" example:"2024-10-01T12:00:00Z" description:"Registration time"`
+}
+
+type CredentialsRequest struct {
+	Username string `json:"username" example:"alice" description:"Login name"`
+	Password string `json:"password" example:"correct horse battery staple" description:"Password, at least 8 characters"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" example:"editor" description:"viewer, editor or admin"`
+}
+
+type TokenResponse struct {
+	AccessToken string    `json:"access_token" example:"eyJhbGciOiJIUzI1NiIs..." description:"Bearer token"`
+	TokenType   string    `json:"token_type" example:"Bearer" description:"Token type"`
+	ExpiresAt   time.Time `json:"expires_at" example:"2024-10-01T13:00:00Z" description:"Token expiration time"`
+}
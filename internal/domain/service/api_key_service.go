@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidApiKey  = errors.New("invalid api key")
+	ErrApiKeyNotFound = errors.New("api key not found")
+	ErrApiKeyRevoked  = errors.New("api key is revoked")
+	// ErrApiKeyExpired — ротация просроченного ключа: замена унаследовала бы его срок и сразу была бы недействительна.
+	ErrApiKeyExpired = errors.New("api key is expired")
+	// ErrInvalidApiKeyInput оборачивает ошибки проверки входных данных; текст ошибки можно показать клиенту.
+	ErrInvalidApiKeyInput = errors.New("invalid api key input")
+)
+
+const (
+	apiKeyPrefix       = "sl_"
+	apiKeyDisplayChars = 10
+	// maxRotationOverlap ограничивает время, в течение которого после ротации работают оба ключа.
+	maxRotationOverlap = 30 * 24 * time.Hour
+)
+
+type ApiKeyService interface {
+	Authenticate(ctx context.Context, rawKey string) (*auth.Principal, error)
+	CreateApiKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*entities.ApiKey, string, error)
+	GetApiKeys(ctx context.Context, limit, offset int) ([]entities.ApiKey, error)
+	RotateApiKey(ctx context.Context, id int, overlap time.Duration) (*entities.ApiKey, string, error)
+	RevokeApiKey(ctx context.Context, id int) error
+}
+
+type ApiKeyServiceImpl struct {
+	apiKeyRepo persistence.ApiKeyRepository
+	tx         persistence.TxManager
+	audit      AuditService
+	logger     *slog.Logger
+}
+
+func NewApiKeyService(apiKeyRepo persistence.ApiKeyRepository, tx persistence.TxManager, audit AuditService, logger *slog.Logger) *ApiKeyServiceImpl {
+	return &ApiKeyServiceImpl{
+		apiKeyRepo: apiKeyRepo,
+		tx:         tx,
+		audit:      audit,
+		logger:     logger.With("service", "ApiKeyService"),
+	}
+}
+
+// Authenticate находит активный ключ по его хэшу и возвращает клиента с выданными ключу скоупами.
+func (s *ApiKeyServiceImpl) Authenticate(ctx context.Context, rawKey string) (*auth.Principal, error) {
+	if rawKey == "" {
+		return nil, ErrInvalidApiKey
+	}
+
+	key, err := s.apiKeyRepo.GetApiKeyByHash(ctx, HashApiKey(rawKey))
+	if err != nil {
+		s.log(ctx).Error("error looking up api key", "error", err)
+		return nil, err
+	}
+	if key == nil || !key.IsActive(time.Now()) {
+		return nil, ErrInvalidApiKey
+	}
+
+	if err := s.apiKeyRepo.TouchApiKey(ctx, key.ID); err != nil {
+		s.log(ctx).Warn("failed to record api key usage", "keyID", key.ID, "error", err)
+	}
+
+	return &auth.Principal{
+		Type:   auth.PrincipalApiKey,
+		ID:     strconv.Itoa(key.ID),
+		Name:   key.Name,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+// CreateApiKey выпускает новый ключ. Открытое значение возвращается только здесь и нигде не хранится.
+func (s *ApiKeyServiceImpl) CreateApiKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*entities.ApiKey, string, error) {
+	if name == "" {
+		err := fmt.Errorf("%w: key name cannot be empty", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid api key name", "error", err)
+		return nil, "", err
+	}
+	if len(scopes) == 0 {
+		err := fmt.Errorf("%w: at least one scope is required", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid api key scopes", "error", err)
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			err := fmt.Errorf("%w: unknown scope %q", ErrInvalidApiKeyInput, scope)
+			s.log(ctx).Error("invalid api key scopes", "error", err)
+			return nil, "", err
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		err := fmt.Errorf("%w: expiration time must be in the future", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid api key expiration", "error", err)
+		return nil, "", err
+	}
+
+	var key *entities.ApiKey
+	var secret string
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		key, secret, err = s.issueApiKey(ctx, name, scopes, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, entities.AuditActionApiKeyCreate, entities.AuditEntityApiKey, strconv.Itoa(key.ID), map[string]any{
+			"name":   key.Name,
+			"scopes": key.Scopes,
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// GetApiKeys возвращает метаданные ключей без секретов.
+func (s *ApiKeyServiceImpl) GetApiKeys(ctx context.Context, limit, offset int) ([]entities.ApiKey, error) {
+	if limit <= 0 {
+		err := fmt.Errorf("%w: limit must be greater than 0", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid limit", "error", err)
+		return nil, err
+	}
+	if offset < 0 {
+		err := fmt.Errorf("%w: offset cannot be negative", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid offset", "error", err)
+		return nil, err
+	}
+
+	return s.apiKeyRepo.GetApiKeys(ctx, limit, offset)
+}
+
+// RotateApiKey выпускает новый ключ с теми же именем, скоупами и сроком действия.
+// Старый ключ продолжает работать ещё overlap, после чего истекает. Просроченный ключ не ротируется:
+// вместо него выпускается новый через CreateApiKey.
+func (s *ApiKeyServiceImpl) RotateApiKey(ctx context.Context, id int, overlap time.Duration) (*entities.ApiKey, string, error) {
+	if overlap < 0 || overlap > maxRotationOverlap {
+		err := fmt.Errorf("%w: overlap must be between 0 and %s", ErrInvalidApiKeyInput, maxRotationOverlap)
+		s.log(ctx).Error("invalid rotation overlap", "error", err)
+		return nil, "", err
+	}
+
+	// Новый ключ и срок действия старого фиксируются вместе: при сбое старый ключ остаётся бессрочным
+	// и не появляется ключ, о котором клиент так и не узнал.
+	var key *entities.ApiKey
+	var secret string
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		old, err := s.getApiKey(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !old.IsActive(time.Now()) {
+			return ErrApiKeyExpired
+		}
+
+		key, secret, err = s.issueApiKey(ctx, old.Name, old.Scopes, old.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		oldExpiresAt := time.Now().Add(overlap)
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(oldExpiresAt) {
+			oldExpiresAt = *old.ExpiresAt
+		}
+		if err := s.apiKeyRepo.SetApiKeyExpiry(ctx, old.ID, oldExpiresAt); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, entities.AuditActionApiKeyRotate, entities.AuditEntityApiKey, strconv.Itoa(old.ID), map[string]any{
+			"new_key_id":     key.ID,
+			"old_expires_at": oldExpiresAt,
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// RevokeApiKey немедленно отзывает ключ.
+func (s *ApiKeyServiceImpl) RevokeApiKey(ctx context.Context, id int) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		key, err := s.getApiKey(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.apiKeyRepo.RevokeApiKey(ctx, key.ID); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, entities.AuditActionApiKeyRevoke, entities.AuditEntityApiKey, strconv.Itoa(key.ID), map[string]any{
+			"name": key.Name,
+		})
+	})
+}
+
+func (s *ApiKeyServiceImpl) getApiKey(ctx context.Context, id int) (*entities.ApiKey, error) {
+	if id <= 0 {
+		err := fmt.Errorf("%w: invalid api key ID", ErrInvalidApiKeyInput)
+		s.log(ctx).Error("invalid api key ID", "error", err)
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.GetApiKeyByID(ctx, id)
+	if err != nil {
+		s.log(ctx).Error("error getting api key", "id", id, "error", err)
+		return nil, err
+	}
+	if key == nil {
+		s.log(ctx).Warn("api key not found", "id", id)
+		return nil, ErrApiKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrApiKeyRevoked
+	}
+
+	return key, nil
+}
+
+func (s *ApiKeyServiceImpl) issueApiKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*entities.ApiKey, string, error) {
+	secret, err := generateApiKey()
+	if err != nil {
+		s.log(ctx).Error("error generating api key", "error", err)
+		return nil, "", err
+	}
+
+	key := &entities.ApiKey{
+		Name:      name,
+		Prefix:    secret[:apiKeyDisplayChars],
+		Hash:      HashApiKey(secret),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.CreateApiKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	s.log(ctx).Info("api key issued", "keyID", key.ID, "name", key.Name, "scopes", key.Scopes)
+	return key, secret, nil
+}
+
+func generateApiKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashApiKey возвращает хэш ключа, под которым он хранится в базе.
+// Ключи генерируются случайно и достаточно длинные, поэтому соль не нужна.
+func HashApiKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ApiKeyServiceImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, s.logger)
+}
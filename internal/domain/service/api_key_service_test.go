@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeApiKeyRepo хранит ключи в памяти.
+type fakeApiKeyRepo struct {
+	keys    map[int]*entities.ApiKey
+	nextID  int
+	touched []int
+}
+
+func newFakeApiKeyRepo(keys ...*entities.ApiKey) *fakeApiKeyRepo {
+	r := &fakeApiKeyRepo{keys: map[int]*entities.ApiKey{}, nextID: 100}
+	for _, k := range keys {
+		r.keys[k.ID] = k
+	}
+	return r
+}
+
+func (r *fakeApiKeyRepo) GetApiKeyByHash(_ context.Context, hash string) (*entities.ApiKey, error) {
+	for _, k := range r.keys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeApiKeyRepo) TouchApiKey(_ context.Context, id int) error {
+	r.touched = append(r.touched, id)
+	return nil
+}
+
+func (r *fakeApiKeyRepo) CreateApiKey(_ context.Context, key *entities.ApiKey) error {
+	r.nextID++
+	key.ID = r.nextID
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r *fakeApiKeyRepo) GetApiKeys(context.Context, int, int) ([]entities.ApiKey, error) {
+	return nil, nil
+}
+
+func (r *fakeApiKeyRepo) GetApiKeyByID(_ context.Context, id int) (*entities.ApiKey, error) {
+	return r.keys[id], nil
+}
+
+func (r *fakeApiKeyRepo) SetApiKeyExpiry(_ context.Context, id int, expiresAt time.Time) error {
+	r.keys[id].ExpiresAt = &expiresAt
+	return nil
+}
+
+func (r *fakeApiKeyRepo) RevokeApiKey(_ context.Context, id int) error {
+	now := time.Now()
+	r.keys[id].RevokedAt = &now
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newTestApiKeyService(repo *fakeApiKeyRepo) (*ApiKeyServiceImpl, *fakeAudit) {
+	audit := &fakeAudit{}
+	return NewApiKeyService(repo, &fakeTx{}, audit, discardLogger()), audit
+}
+
+func TestApiKeyAuthenticate(t *testing.T) {
+	now := time.Now()
+	key := func(id int, secret string, expiresAt, revokedAt *time.Time) *entities.ApiKey {
+		return &entities.ApiKey{
+			ID: id, Name: "key-" + strconv.Itoa(id), Hash: HashApiKey(secret),
+			Scopes: []string{auth.ScopeSongsRead}, ExpiresAt: expiresAt, RevokedAt: revokedAt,
+		}
+	}
+	repo := newFakeApiKeyRepo(
+		key(1, "sl_active", nil, nil),
+		key(2, "sl_expiring", ptr(now.Add(time.Hour)), nil),
+		key(3, "sl_expired", ptr(now.Add(-time.Second)), nil),
+		key(4, "sl_revoked", nil, ptr(now.Add(-time.Hour))),
+	)
+	s, _ := newTestApiKeyService(repo)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantID  string
+		wantErr error
+	}{
+		{name: "active key", raw: "sl_active", wantID: "1"},
+		{name: "key before expiry", raw: "sl_expiring", wantID: "2"},
+		{name: "expired key", raw: "sl_expired", wantErr: ErrInvalidApiKey},
+		{name: "revoked key", raw: "sl_revoked", wantErr: ErrInvalidApiKey},
+		{name: "unknown key", raw: "sl_unknown", wantErr: ErrInvalidApiKey},
+		{name: "empty key", raw: "", wantErr: ErrInvalidApiKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := s.Authenticate(context.Background(), tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.Type != auth.PrincipalApiKey || principal.ID != tt.wantID {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+			if !slices.Equal(principal.Scopes, []string{auth.ScopeSongsRead}) {
+				t.Errorf("scopes = %v", principal.Scopes)
+			}
+		})
+	}
+
+	if !slices.Equal(repo.touched, []int{1, 2}) {
+		t.Errorf("touched keys = %v, want only the accepted ones [1 2]", repo.touched)
+	}
+}
+
+func TestCreateApiKeyValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+	}{
+		{name: "empty name", scopes: []string{auth.ScopeSongsRead}},
+		{name: "no scopes", keyName: "app"},
+		{name: "unknown scope", keyName: "app", scopes: []string{"songs:everything"}},
+		{name: "expiry in the past", keyName: "app", scopes: []string{auth.ScopeSongsRead}, expiresAt: ptr(time.Now().Add(-time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestApiKeyService(newFakeApiKeyRepo())
+			_, _, err := s.CreateApiKey(context.Background(), tt.keyName, tt.scopes, tt.expiresAt)
+			if !errors.Is(err, ErrInvalidApiKeyInput) {
+				t.Fatalf("got %v, want ErrInvalidApiKeyInput", err)
+			}
+		})
+	}
+}
+
+func TestCreateApiKeyReturnsWorkingSecret(t *testing.T) {
+	repo := newFakeApiKeyRepo()
+	s, audit := newTestApiKeyService(repo)
+
+	key, secret, err := s.CreateApiKey(context.Background(), "app", []string{auth.ScopeSongsWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Hash == secret || key.Hash != HashApiKey(secret) {
+		t.Error("only the hash of the secret must be stored")
+	}
+	if _, err := s.Authenticate(context.Background(), secret); err != nil {
+		t.Errorf("issued secret must authenticate: %v", err)
+	}
+	if !slices.Equal(audit.actions, []string{entities.AuditActionApiKeyCreate}) {
+		t.Errorf("audit actions = %v", audit.actions)
+	}
+}
+
+func TestRotateApiKey(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		key         *entities.ApiKey
+		overlap     time.Duration
+		wantErr     error
+		wantOldLeft time.Duration // сколько ещё работает старый ключ
+	}{
+		{
+			name:        "key without expiry",
+			key:         &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}},
+			overlap:     time.Hour,
+			wantOldLeft: time.Hour,
+		},
+		{
+			name:        "overlap is clamped to the old expiry",
+			key:         &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}, ExpiresAt: ptr(now.Add(10 * time.Minute))},
+			overlap:     time.Hour,
+			wantOldLeft: 10 * time.Minute,
+		},
+		{
+			name:    "expired key",
+			key:     &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}, ExpiresAt: ptr(now.Add(-time.Minute))},
+			overlap: time.Hour,
+			wantErr: ErrApiKeyExpired,
+		},
+		{
+			name:    "revoked key",
+			key:     &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}, RevokedAt: ptr(now.Add(-time.Minute))},
+			overlap: time.Hour,
+			wantErr: ErrApiKeyRevoked,
+		},
+		{
+			name:    "negative overlap",
+			key:     &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}},
+			overlap: -time.Second,
+			wantErr: ErrInvalidApiKeyInput,
+		},
+		{
+			name:    "overlap too long",
+			key:     &entities.ApiKey{ID: 1, Name: "app", Scopes: []string{auth.ScopeSongsRead}},
+			overlap: maxRotationOverlap + time.Hour,
+			wantErr: ErrInvalidApiKeyInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeApiKeyRepo(tt.key)
+			s, audit := newTestApiKeyService(repo)
+			oldExpiresAt := tt.key.ExpiresAt
+
+			key, secret, err := s.RotateApiKey(context.Background(), tt.key.ID, tt.overlap)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.keys) != 1 {
+					t.Error("no replacement key must be issued")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if key.Name != tt.key.Name || !slices.Equal(key.Scopes, tt.key.Scopes) {
+				t.Errorf("replacement %+v must keep name and scopes of %+v", key, tt.key)
+			}
+			if (key.ExpiresAt == nil) != (oldExpiresAt == nil) {
+				t.Errorf("replacement expiry = %v, want %v", key.ExpiresAt, oldExpiresAt)
+			}
+			if key.Hash != HashApiKey(secret) {
+				t.Error("replacement hash does not match its secret")
+			}
+
+			left := time.Until(*repo.keys[tt.key.ID].ExpiresAt)
+			if left > tt.wantOldLeft || left < tt.wantOldLeft-time.Minute {
+				t.Errorf("old key works for another %s, want about %s", left, tt.wantOldLeft)
+			}
+			if !slices.Equal(audit.actions, []string{entities.AuditActionApiKeyRotate}) {
+				t.Errorf("audit actions = %v", audit.actions)
+			}
+		})
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     *entities.ApiKey
+		id      int
+		wantErr error
+	}{
+		{name: "active key", key: &entities.ApiKey{ID: 1, Hash: HashApiKey("sl_a")}, id: 1},
+		{name: "expired key can still be revoked", key: &entities.ApiKey{ID: 1, Hash: HashApiKey("sl_a"), ExpiresAt: ptr(time.Now().Add(-time.Hour))}, id: 1},
+		{name: "already revoked", key: &entities.ApiKey{ID: 1, RevokedAt: ptr(time.Now())}, id: 1, wantErr: ErrApiKeyRevoked},
+		{name: "unknown key", key: &entities.ApiKey{ID: 1}, id: 2, wantErr: ErrApiKeyNotFound},
+		{name: "invalid id", key: &entities.ApiKey{ID: 1}, id: 0, wantErr: ErrInvalidApiKeyInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeApiKeyRepo(tt.key)
+			s, audit := newTestApiKeyService(repo)
+
+			err := s.RevokeApiKey(context.Background(), tt.id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.keys[tt.id].RevokedAt == nil {
+				t.Error("key must be revoked")
+			}
+			if _, err := s.Authenticate(context.Background(), "sl_a"); !errors.Is(err, ErrInvalidApiKey) {
+				t.Errorf("revoked key authenticated: %v", err)
+			}
+			if !slices.Equal(audit.actions, []string{entities.AuditActionApiKeyRevoke}) {
+				t.Errorf("audit actions = %v", audit.actions)
+			}
+		})
+	}
+}
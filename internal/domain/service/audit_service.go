@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+type AuditService interface {
+	Record(ctx context.Context, action, entityType, entityID string, details map[string]any) error
+	RecordSongChange(ctx context.Context, action string, songID int, before, after *entities.Song, details map[string]any) error
+	GetAuditEntries(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]entities.AuditEntry, error)
+}
+
+type AuditServiceImpl struct {
+	auditRepo persistence.AuditRepository
+	logger    *slog.Logger
+}
+
+func NewAuditService(auditRepo persistence.AuditRepository, logger *slog.Logger) *AuditServiceImpl {
+	return &AuditServiceImpl{
+		auditRepo: auditRepo,
+		logger:    logger.With("service", "AuditService"),
+	}
+}
+
+// Record записывает действие в журнал аудита от имени клиента из контекста.
+func (s *AuditServiceImpl) Record(ctx context.Context, action, entityType, entityID string, details map[string]any) error {
+	return s.record(ctx, entities.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    details,
+	})
+}
+
+// RecordSongChange записывает изменение песни вместе с хешами её состояния до и после.
+// Для создания before равен nil, для удаления — after.
+func (s *AuditServiceImpl) RecordSongChange(ctx context.Context, action string, songID int, before, after *entities.Song, details map[string]any) error {
+	return s.record(ctx, entities.AuditEntry{
+		Action:     action,
+		EntityType: entities.AuditEntitySong,
+		EntityID:   strconv.Itoa(songID),
+		BeforeHash: songHash(before),
+		AfterHash:  songHash(after),
+		Details:    details,
+	})
+}
+
+func (s *AuditServiceImpl) GetAuditEntries(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]entities.AuditEntry, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, ErrInvalidAuditFilter
+	}
+
+	entries, err := s.auditRepo.GetAuditEntries(ctx, filter, limit, offset)
+	if err != nil {
+		s.log(ctx).Error("failed to get audit entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *AuditServiceImpl) record(ctx context.Context, entry entities.AuditEntry) error {
+	entry.ActorType = "system"
+	entry.ActorID = "system"
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		entry.ActorType = principal.Type
+		entry.ActorID = principal.ID
+		entry.ActorName = principal.Name
+	}
+	entry.RequestID = requestctx.RequestID(ctx)
+	entry.ClientIP = requestctx.ClientIP(ctx)
+
+	if err := s.auditRepo.CreateAuditEntry(ctx, &entry); err != nil {
+		s.log(ctx).Error("failed to record audit entry", "action", entry.Action, "entityID", entry.EntityID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// songHash хеширует пользовательское содержимое песни; служебные поля
+// (enriched_at, provenance) не влияют на хеш, чтобы он менялся только при реальной правке.
+func songHash(song *entities.Song) string {
+	if song == nil {
+		return ""
+	}
+
+	content := struct {
+		Group       string `json:"group"`
+		Song        string `json:"song"`
+		ReleaseDate string `json:"release_date"`
+		Text        string `json:"text"`
+		Link        string `json:"link"`
+		OwnerID     *int   `json:"owner_id"`
+		Visibility  string `json:"visibility"`
+	}{
+		Group:       song.Group,
+		Song:        song.Song,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+		OwnerID:     song.OwnerID,
+		Visibility:  song.Visibility,
+	}
+
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuditServiceImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, s.logger)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeAuditRepo хранит записи журнала в памяти.
+type fakeAuditRepo struct {
+	entries []entities.AuditEntry
+	err     error
+	queried bool
+}
+
+func (r *fakeAuditRepo) CreateAuditEntry(_ context.Context, entry *entities.AuditEntry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeAuditRepo) GetAuditEntries(context.Context, entities.AuditFilter, int, int) ([]entities.AuditEntry, error) {
+	r.queried = true
+	return r.entries, r.err
+}
+
+func TestAuditRecordsActorFromContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantType  string
+		wantID    string
+	}{
+		{name: "request principal", principal: &auth.Principal{Type: auth.PrincipalApiKey, ID: "3", Name: "ci"}, wantType: auth.PrincipalApiKey, wantID: "3"},
+		{name: "background job", principal: nil, wantType: "system", wantID: "system"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAuditRepo{}
+			s := NewAuditService(repo, discardLogger())
+
+			ctx := requestctx.WithClientIP(requestctx.WithRequestID(context.Background(), "req-1"), "10.0.0.7")
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			if err := s.Record(ctx, "api_key.create", "api_key", "9", map[string]any{"name": "ci"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(repo.entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(repo.entries))
+			}
+			got := repo.entries[0]
+			if got.ActorType != tt.wantType || got.ActorID != tt.wantID {
+				t.Errorf("actor = %s/%s, want %s/%s", got.ActorType, got.ActorID, tt.wantType, tt.wantID)
+			}
+			if got.RequestID != "req-1" || got.ClientIP != "10.0.0.7" {
+				t.Errorf("request id %q, client ip %q must come from the context", got.RequestID, got.ClientIP)
+			}
+			if got.Action != "api_key.create" || got.EntityType != "api_key" || got.EntityID != "9" {
+				t.Errorf("unexpected entry %+v", got)
+			}
+		})
+	}
+}
+
+func TestAuditSongChangeHashes(t *testing.T) {
+	repo := &fakeAuditRepo{}
+	s := NewAuditService(repo, discardLogger())
+	ctx := context.Background()
+
+	song := &entities.Song{Group: "Muse", Song: "Hysteria", Text: "It's bugging me"}
+	enriched := *song
+	enriched.EnrichedAt = &time.Time{}
+	edited := *song
+	edited.Text = "Grating me"
+
+	if err := s.RecordSongChange(ctx, "song.create", 1, nil, song, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordSongChange(ctx, "song.refresh", 1, song, &enriched, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordSongChange(ctx, "song.update", 1, song, &edited, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordSongChange(ctx, "song.delete", 1, &edited, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	created, refreshed, updated, deleted := repo.entries[0], repo.entries[1], repo.entries[2], repo.entries[3]
+	if created.BeforeHash != "" || created.AfterHash == "" {
+		t.Errorf("create must only have an after hash: %+v", created)
+	}
+	if refreshed.BeforeHash != refreshed.AfterHash {
+		t.Error("service fields must not change the hash")
+	}
+	if updated.BeforeHash == updated.AfterHash {
+		t.Error("content change must change the hash")
+	}
+	if deleted.BeforeHash != updated.AfterHash || deleted.AfterHash != "" {
+		t.Errorf("delete must only have a before hash matching the last state: %+v", deleted)
+	}
+	if created.EntityType != entities.AuditEntitySong || created.EntityID != "1" {
+		t.Errorf("unexpected entity %s/%s", created.EntityType, created.EntityID)
+	}
+}
+
+func TestAuditRecordReturnsRepositoryError(t *testing.T) {
+	repoErr := errors.New("connection reset")
+	s := NewAuditService(&fakeAuditRepo{err: repoErr}, discardLogger())
+
+	if err := s.Record(context.Background(), "song.create", "song", "1", nil); !errors.Is(err, repoErr) {
+		t.Errorf("got %v, want %v", err, repoErr)
+	}
+}
+
+func TestGetAuditEntriesRejectsEmptyRange(t *testing.T) {
+	from := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		to      time.Time
+		wantErr error
+	}{
+		{name: "to before from", to: from.Add(-time.Hour), wantErr: ErrInvalidAuditFilter},
+		{name: "to equals from", to: from, wantErr: ErrInvalidAuditFilter},
+		{name: "to after from", to: from.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAuditRepo{}
+			s := NewAuditService(repo, discardLogger())
+
+			_, err := s.GetAuditEntries(context.Background(), entities.AuditFilter{From: &from, To: &tt.to}, 10, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if repo.queried != (tt.wantErr == nil) {
+				t.Errorf("repository queried = %v", repo.queried)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// EnrichmentScheduler периодически запускает повторное обогащение устаревших песен.
+type EnrichmentScheduler struct {
+	songService SongService
+	logger      *slog.Logger
+	interval    time.Duration
+	maxAge      time.Duration
+	batchSize   int
+}
+
+func NewEnrichmentScheduler(songService SongService, logger *slog.Logger, interval, maxAge time.Duration, batchSize int) *EnrichmentScheduler {
+	return &EnrichmentScheduler{
+		songService: songService,
+		logger:      logger.With("worker", "EnrichmentScheduler"),
+		interval:    interval,
+		maxAge:      maxAge,
+		batchSize:   batchSize,
+	}
+}
+
+// Run выполняет обогащение каждые interval до отмены ctx.
+// За один проход обрабатываются все устаревшие песни пачками по batchSize.
+func (s *EnrichmentScheduler) Run(ctx context.Context) {
+	s.logger.Info("enrichment scheduler started", "interval", s.interval, "maxAge", s.maxAge)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("enrichment scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EnrichmentScheduler) refresh(ctx context.Context) {
+	total := 0
+	for {
+		n, err := s.songService.RefreshStaleSongs(ctx, s.maxAge, s.batchSize)
+		total += n
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("enrichment pass failed", "error", err, "processed", total)
+			}
+			return
+		}
+		if n < s.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("enrichment pass finished", "processed", total)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"io"
+	"log/slog"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeTx выполняет fn без транзакции и считает вызовы.
+type fakeTx struct {
+	calls int
+}
+
+func (t *fakeTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.calls++
+	return fn(ctx)
+}
+
+// fakeAudit запоминает действия, записанные в журнал.
+type fakeAudit struct {
+	actions []string
+}
+
+func (a *fakeAudit) Record(_ context.Context, action, _, _ string, _ map[string]any) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func (a *fakeAudit) RecordSongChange(_ context.Context, action string, _ int, _, _ *entities.Song, _ map[string]any) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func (a *fakeAudit) GetAuditEntries(context.Context, entities.AuditFilter, int, int) ([]entities.AuditEntry, error) {
+	return nil, nil
+}
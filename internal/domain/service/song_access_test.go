@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"testing"
+)
+
+func TestIsOwnerOrAdmin(t *testing.T) {
+	owned := &entities.Song{ID: 1, OwnerID: ptr(7)}
+	unowned := &entities.Song{ID: 2}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		song      *entities.Song
+		want      bool
+	}{
+		{name: "no principal", principal: nil, song: owned, want: false},
+		{name: "no principal, unowned song", principal: nil, song: unowned, want: false},
+		{name: "admin", principal: &auth.Principal{Type: auth.PrincipalApiKey, Scopes: []string{auth.ScopeAdmin}}, song: owned, want: true},
+		{name: "owner", principal: &auth.Principal{Type: auth.PrincipalUser, UserID: "7", Scopes: []string{auth.ScopeSongsWrite}}, song: owned, want: true},
+		{name: "another user", principal: &auth.Principal{Type: auth.PrincipalUser, UserID: "8", Scopes: []string{auth.ScopeSongsWrite}}, song: owned, want: false},
+		{name: "user and unowned song", principal: &auth.Principal{Type: auth.PrincipalUser, UserID: "7", Scopes: []string{auth.ScopeSongsWrite}}, song: unowned, want: false},
+		{name: "external jwt with the same id", principal: &auth.Principal{Type: auth.PrincipalJWT, UserID: "7", Scopes: []string{auth.ScopeSongsWrite}}, song: owned, want: false},
+		{name: "api key", principal: &auth.Principal{Type: auth.PrincipalApiKey, ID: "7", Scopes: []string{auth.ScopeSongsWrite}}, song: owned, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			if got := isOwnerOrAdmin(ctx, tt.song); got != tt.want {
+				t.Errorf("isOwnerOrAdmin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
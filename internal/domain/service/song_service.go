@@ -2,13 +2,36 @@ package service
 
 import (
 	"context"
+	"effictiveMobile/internal/domain/auth"
 	"effictiveMobile/internal/domain/entities"
 	"effictiveMobile/internal/infrastrtucture/external_api"
 	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/internal/infrastrtucture/tracing"
+	"effictiveMobile/pkg/requestctx"
 	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
 	"log/slog"
 	"net/url"
+	"strconv"
+	"time"
+)
+
+// tracerName — имя трассировщика для спанов бизнес-операций.
+const tracerName = "effictiveMobile/service"
+
+var (
+	ErrSongExists       = errors.New("song already exists")
+	ErrProposalNotFound = errors.New("change proposal not found")
+	ErrProposalResolved = errors.New("change proposal is already resolved")
+	// ErrInvalidSongInput оборачивает ошибки проверки параметров и данных песни; текст ошибки можно показать клиенту.
+	ErrInvalidSongInput = errors.New("invalid song input")
+
+	// ErrVisibilityForbidden возвращается, если видимость песни меняет не владелец и не администратор.
+	ErrVisibilityForbidden = errors.New("only the owner or an admin can change song visibility")
+	// ErrUnownedSongVisibility возвращается при попытке скрыть песню без владельца:
+	// её не увидел бы никто, кроме администраторов.
+	ErrUnownedSongVisibility = errors.New("a song without an owner must be public")
 )
 
 type SongService interface {
@@ -17,39 +40,61 @@ type SongService interface {
 	CreateSong(ctx context.Context, song *entities.Song) error
 	UpdateSong(ctx context.Context, id int, song *entities.Song) error
 	DeleteSong(ctx context.Context, id int) error
+	ReEnrichSong(ctx context.Context, id int) (*entities.Song, error)
 	GetSongDetails(ctx context.Context, group, song string) (*external_api.SongDetail, error) // Новый метод
+	RefreshStaleSongs(ctx context.Context, maxAge time.Duration, limit int) (int, error)
+	GetChangeProposals(ctx context.Context, status string, limit, offset int) ([]entities.SongChangeProposal, error)
+	AcceptChangeProposal(ctx context.Context, id int) error
+	RejectChangeProposal(ctx context.Context, id int) error
+}
+
+// SongMetrics считает доменные события библиотеки.
+type SongMetrics interface {
+	SongCreated()
+	SongDeleted()
 }
 
 type SongServiceImpl struct {
-	songRepo  persistence.SongRepository
-	logger    *slog.Logger
-	apiClient *external_api.Client
+	songRepo   persistence.SongRepository
+	changeRepo persistence.SongChangeRepository
+	tx         persistence.TxManager
+	audit      AuditService
+	metrics    SongMetrics
+	logger     *slog.Logger
+	apiClient  *external_api.Client
 }
 
-func NewSongService(songRepo persistence.SongRepository, logger *slog.Logger, apiClient *external_api.Client) *SongServiceImpl {
+func NewSongService(songRepo persistence.SongRepository, changeRepo persistence.SongChangeRepository, tx persistence.TxManager, audit AuditService, metrics SongMetrics, logger *slog.Logger, apiClient *external_api.Client) *SongServiceImpl {
 	return &SongServiceImpl{
-		songRepo:  songRepo,
-		logger:    logger.With("service", "SongService"),
-		apiClient: apiClient,
+		songRepo:   songRepo,
+		changeRepo: changeRepo,
+		tx:         tx,
+		audit:      audit,
+		metrics:    metrics,
+		logger:     logger.With("service", "SongService"),
+		apiClient:  apiClient,
 	}
 }
 
 // GetSongs валидирует и фильтрует данные перед вызовом репозитория.
-func (s *SongServiceImpl) GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]entities.Song, error) {
+func (s *SongServiceImpl) GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) (_ []entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.GetSongs")
+	defer tracing.End(span, &err)
+
 	if limit <= 0 {
-		err := errors.New("limit must be greater than 0")
-		s.logger.Error("invalid limit", "error", err)
+		err := fmt.Errorf("%w: limit must be greater than 0", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid limit", "error", err)
 		return nil, err
 	}
 	if offset < 0 {
-		err := errors.New("offset cannot be negative")
-		s.logger.Error("invalid offset", "error", err)
+		err := fmt.Errorf("%w: offset cannot be negative", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid offset", "error", err)
 		return nil, err
 	}
 
 	if group, ok := filter["group"]; ok && group == "" {
-		err := errors.New("group filter cannot be empty")
-		s.logger.Error("invalid group filter", "error", err)
+		err := fmt.Errorf("%w: group filter cannot be empty", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid group filter", "error", err)
 		return nil, err
 	}
 
@@ -57,22 +102,31 @@ func (s *SongServiceImpl) GetSongs(ctx context.Context, filter map[string]interf
 }
 
 // GetSongByID валидирует ID и вызывает репозиторий для получения песни.
-func (s *SongServiceImpl) GetSongByID(ctx context.Context, id int) (*entities.Song, error) {
+func (s *SongServiceImpl) GetSongByID(ctx context.Context, id int) (_ *entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.GetSongByID", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
 	if id <= 0 {
-		err := errors.New("invalid song ID")
-		s.logger.Error("invalid song ID", "error", err)
+		err := fmt.Errorf("%w: invalid song ID", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid song ID", "error", err)
 		return nil, err
 	}
 
 	song, err := s.songRepo.GetSongByID(ctx, id)
 	if err != nil {
-		s.logger.Error("error getting song by ID", "id", id, "error", err)
+		s.log(ctx).Error("error getting song by ID", "id", id, "error", err)
 		return nil, err
 	}
 
 	if song == nil {
-		err := fmt.Errorf("song with ID %d not found", id)
-		s.logger.Warn("song not found", "id", id)
+		err := fmt.Errorf("song with ID %d: %w", id, persistence.ErrSongNotFound)
+		s.log(ctx).Warn("song not found", "id", id)
+		return nil, err
+	}
+
+	song.Provenance, err = s.songRepo.GetFieldProvenance(ctx, id)
+	if err != nil {
+		s.log(ctx).Error("error getting song provenance", "id", id, "error", err)
 		return nil, err
 	}
 
@@ -80,52 +134,242 @@ func (s *SongServiceImpl) GetSongByID(ctx context.Context, id int) (*entities.So
 }
 
 // CreateSong валидирует входные данные и вызывает репозиторий для создания новой песни.
-func (s *SongServiceImpl) CreateSong(ctx context.Context, song *entities.Song) error {
+// Дата выхода, текст и ссылка считаются полученными из внешнего API.
+// Песня, созданная пользователем, принадлежит ему; видимость по умолчанию — public.
+// Если у того же владельца (или в библиотеке) уже есть песня с такой группой и названием,
+// возвращается ErrSongExists; обновить её детали можно через ReEnrichSong.
+func (s *SongServiceImpl) CreateSong(ctx context.Context, song *entities.Song) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.CreateSong")
+	defer tracing.End(span, &err)
+
+	if song.Visibility == "" {
+		song.Visibility = entities.VisibilityPublic
+	}
+	song.OwnerID = nil
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && principal.Type == auth.PrincipalUser {
+		if ownerID, err := strconv.Atoi(principal.UserID); err == nil {
+			song.OwnerID = &ownerID
+		}
+	}
+
 	if err := validateSong(song); err != nil {
-		s.logger.Error("validation error while creating song", "error", err)
+		s.log(ctx).Error("validation error while creating song", "error", err)
 		return err
 	}
+	if song.OwnerID == nil && song.Visibility != entities.VisibilityPublic {
+		s.log(ctx).Warn("unowned song must be public", "visibility", song.Visibility)
+		return ErrUnownedSongVisibility
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		existing, err := s.songRepo.GetSongByGroupAndTitle(ctx, song.Group, song.Song, song.OwnerID)
+		if err != nil {
+			s.log(ctx).Error("error looking up existing song", "song", song, "error", err)
+			return err
+		}
+		if existing != nil {
+			s.log(ctx).Warn("song already exists", "songID", existing.ID)
+			return ErrSongExists
+		}
+
+		err = s.songRepo.CreateSong(ctx, song)
+		if err != nil {
+			s.log(ctx).Error("error creating song", "song", song, "error", err)
+			return err
+		}
+
+		if err := s.recordUpstreamProvenance(ctx, song.ID, entities.EnrichableSongFields, time.Now()); err != nil {
+			return err
+		}
 
-	err := s.songRepo.CreateSong(ctx, song)
+		return s.audit.RecordSongChange(ctx, entities.AuditActionSongCreate, song.ID, nil, song, nil)
+	})
 	if err != nil {
-		s.logger.Error("error creating song", "song", song, "error", err)
+		return err
 	}
-	return err
+
+	// Счётчик увеличивается только после фиксации, чтобы откаченные вставки не попадали в метрики.
+	s.metrics.SongCreated()
+	return nil
 }
 
-// UpdateSong валидирует данные и вызывает репозиторий для обновления песни.
-func (s *SongServiceImpl) UpdateSong(ctx context.Context, id int, song *entities.Song) error {
-	if id <= 0 {
-		err := errors.New("invalid song ID")
-		s.logger.Error("invalid song ID", "error", err)
+// ReEnrichSong заново запрашивает детали песни во внешнем API и переписывает ими все поля,
+// кроме исправленных вручную, в том числе поля с неизвестным происхождением.
+// Возвращает итоговое состояние песни вместе с происхождением полей.
+func (s *SongServiceImpl) ReEnrichSong(ctx context.Context, id int) (_ *entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.ReEnrichSong", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	song, err := s.GetSongByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.apiClient.GetSongDetails(ctx, song.Group, song.Song)
+	if err != nil {
+		s.log(ctx).Error("error getting song details", "songID", id, "error", err)
+		return nil, err
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Песню перечитываем в транзакции: пока шёл запрос к API, её могли изменить.
+		existing, err := s.songRepo.GetSongByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return persistence.ErrSongNotFound
+		}
+		return s.reEnrichSong(ctx, existing, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSongByID(ctx, id)
+}
+
+// reEnrichSong применяет свежие детали к существующей песне, не трогая поля, исправленные вручную.
+func (s *SongServiceImpl) reEnrichSong(ctx context.Context, existing *entities.Song, details *external_api.SongDetail) error {
+	before := *existing
+
+	provenance, err := s.songRepo.GetFieldProvenance(ctx, existing.ID)
+	if err != nil {
+		s.log(ctx).Error("error getting song provenance", "songID", existing.ID, "error", err)
 		return err
 	}
 
-	if err := validateSong(song); err != nil {
-		s.logger.Error("validation error while updating song", "error", err)
+	var refreshed []string
+	for _, field := range entities.EnrichableSongFields {
+		if p, ok := provenance[field]; ok && p.ManuallyOverridden {
+			s.log(ctx).Info("keeping manually overridden field", "songID", existing.ID, "field", field)
+			continue
+		}
+		entities.SetSongFieldValue(existing, field, detailFieldValue(details, field))
+		refreshed = append(refreshed, field)
+	}
+
+	if err := s.songRepo.UpdateSong(ctx, existing.ID, existing); err != nil {
+		s.log(ctx).Error("error updating existing song", "songID", existing.ID, "error", err)
 		return err
 	}
 
-	err := s.songRepo.UpdateSong(ctx, id, song)
-	if err != nil {
-		s.logger.Error("error updating song", "songID", id, "song", song, "error", err)
+	now := time.Now()
+	if err := s.songRepo.MarkSongEnriched(ctx, existing.ID, now); err != nil {
+		return err
 	}
-	return err
+	existing.EnrichedAt = &now
+
+	if err := s.recordUpstreamProvenance(ctx, existing.ID, refreshed, now); err != nil {
+		return err
+	}
+
+	return s.audit.RecordSongChange(ctx, entities.AuditActionSongMerge, existing.ID, &before, existing, map[string]any{
+		"refreshed_fields": refreshed,
+	})
+}
+
+// UpdateSong валидирует данные и вызывает репозиторий для обновления песни.
+func (s *SongServiceImpl) UpdateSong(ctx context.Context, id int, song *entities.Song) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.UpdateSong", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	if id <= 0 {
+		err := fmt.Errorf("%w: invalid song ID", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid song ID", "error", err)
+		return err
+	}
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.songRepo.GetSongByID(ctx, id)
+		if err != nil {
+			s.log(ctx).Error("error getting song by ID", "id", id, "error", err)
+			return err
+		}
+		if current == nil {
+			s.log(ctx).Warn("song not found", "id", id)
+			return persistence.ErrSongNotFound
+		}
+
+		if song.Visibility == "" {
+			song.Visibility = current.Visibility
+		}
+		if err := validateSong(song); err != nil {
+			s.log(ctx).Error("validation error while updating song", "error", err)
+			return err
+		}
+		if song.Visibility != current.Visibility {
+			if current.OwnerID == nil {
+				s.log(ctx).Warn("unowned song must be public", "songID", id, "visibility", song.Visibility)
+				return ErrUnownedSongVisibility
+			}
+			if !isOwnerOrAdmin(ctx, current) {
+				s.log(ctx).Warn("visibility change by non-owner", "songID", id)
+				return ErrVisibilityForbidden
+			}
+		}
+
+		err = s.songRepo.UpdateSong(ctx, id, song)
+		if err != nil {
+			s.log(ctx).Error("error updating song", "songID", id, "song", song, "error", err)
+			return err
+		}
+
+		// Изменённые редактором поля больше не перезаписываются данными из внешнего API.
+		for _, field := range entities.EnrichableSongFields {
+			if entities.SongFieldValue(current, field) == entities.SongFieldValue(song, field) {
+				continue
+			}
+			err := s.songRepo.SaveFieldProvenance(ctx, id, field, entities.FieldProvenance{
+				Source:             entities.ProvenanceSourceManual,
+				ManuallyOverridden: true,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		after := *current
+		after.Group, after.Song, after.Visibility = song.Group, song.Song, song.Visibility
+		for _, field := range entities.EnrichableSongFields {
+			entities.SetSongFieldValue(&after, field, entities.SongFieldValue(song, field))
+		}
+		return s.audit.RecordSongChange(ctx, entities.AuditActionSongUpdate, id, current, &after, nil)
+	})
 }
 
 // DeleteSong валидирует ID перед удалением песни.
-func (s *SongServiceImpl) DeleteSong(ctx context.Context, id int) error {
+func (s *SongServiceImpl) DeleteSong(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.DeleteSong", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
 	if id <= 0 {
-		err := errors.New("invalid song ID")
-		s.logger.Error("invalid song ID", "error", err)
+		err := fmt.Errorf("%w: invalid song ID", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid song ID", "error", err)
 		return err
 	}
 
-	err := s.songRepo.DeleteSong(ctx, id)
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := s.songRepo.GetSongByID(ctx, id)
+		if err != nil {
+			s.log(ctx).Error("error getting song by ID", "id", id, "error", err)
+			return err
+		}
+
+		err = s.songRepo.DeleteSong(ctx, id)
+		if err != nil {
+			s.log(ctx).Error("error deleting song", "songID", id, "error", err)
+			return err
+		}
+
+		return s.audit.RecordSongChange(ctx, entities.AuditActionSongDelete, id, before, nil, nil)
+	})
 	if err != nil {
-		s.logger.Error("error deleting song", "songID", id, "error", err)
+		return err
 	}
-	return err
+
+	s.metrics.SongDeleted()
+	return nil
 }
 
 // GetSongDetails получает детали о песне из внешнего API
@@ -133,21 +377,304 @@ func (s *SongServiceImpl) GetSongDetails(ctx context.Context, group, song string
 	return s.apiClient.GetSongDetails(ctx, group, song)
 }
 
+// RefreshStaleSongs повторно запрашивает детали песен, обогащённых раньше чем maxAge назад.
+// Поля с известным внешним происхождением обновляются сразу. Расхождения в полях, исправленных
+// вручную или с неизвестным происхождением, не перезаписываются, а сохраняются как предложенные изменения.
+// Песня, детали которой получить не удалось, откладывается до следующего окна maxAge.
+// Возвращает количество обработанных песен, включая неудачные попытки.
+func (s *SongServiceImpl) RefreshStaleSongs(ctx context.Context, maxAge time.Duration, limit int) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.RefreshStaleSongs")
+	defer tracing.End(span, &err)
+
+	songs, err := s.songRepo.GetStaleSongs(ctx, time.Now().Add(-maxAge), limit)
+	if err != nil {
+		s.log(ctx).Error("error getting stale songs", "error", err)
+		return 0, err
+	}
+
+	processed := 0
+	for _, song := range songs {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+
+		details, err := s.apiClient.GetSongDetails(ctx, song.Group, song.Song)
+		if err != nil {
+			if ctx.Err() != nil {
+				return processed, ctx.Err()
+			}
+			s.log(ctx).Warn("failed to refresh song details", "songID", song.ID, "error", err)
+			// Без отметки песня осталась бы в начале выборки и занимала бы место в каждой пачке.
+			if err := s.songRepo.MarkSongEnrichFailed(ctx, song.ID, time.Now()); err != nil {
+				return processed, err
+			}
+			processed++
+			continue
+		}
+
+		// Запрос к внешнему API выполнен до начала транзакции, чтобы не держать её открытой на время сети.
+		err = s.tx.InTx(ctx, func(ctx context.Context) error {
+			return s.applySongDetails(ctx, &song, details, time.Now())
+		})
+		if err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (s *SongServiceImpl) applySongDetails(ctx context.Context, song *entities.Song, details *external_api.SongDetail, fetchedAt time.Time) error {
+	provenance, err := s.songRepo.GetFieldProvenance(ctx, song.ID)
+	if err != nil {
+		s.log(ctx).Error("error getting song provenance", "songID", song.ID, "error", err)
+		return err
+	}
+
+	before := *song
+	var confirmed, updated []string
+	for _, field := range entities.EnrichableSongFields {
+		current := entities.SongFieldValue(song, field)
+		latest := detailFieldValue(details, field)
+		p, known := provenance[field]
+
+		if latest == "" || latest == current {
+			if !p.ManuallyOverridden {
+				confirmed = append(confirmed, field)
+			}
+			continue
+		}
+
+		if !known || p.ManuallyOverridden {
+			change := entities.SongChangeProposal{
+				SongID:        song.ID,
+				Field:         field,
+				CurrentValue:  current,
+				ProposedValue: latest,
+			}
+			if err := s.changeRepo.SaveProposal(ctx, &change); err != nil {
+				return err
+			}
+			s.log(ctx).Info("song change proposed", "songID", song.ID, "field", field, "proposalID", change.ID)
+			continue
+		}
+
+		if err := s.songRepo.UpdateSongField(ctx, song.ID, field, latest); err != nil {
+			return err
+		}
+		s.log(ctx).Info("song field refreshed", "songID", song.ID, "field", field)
+		entities.SetSongFieldValue(song, field, latest)
+		confirmed = append(confirmed, field)
+		updated = append(updated, field)
+	}
+
+	if err := s.recordUpstreamProvenance(ctx, song.ID, confirmed, fetchedAt); err != nil {
+		return err
+	}
+
+	if err := s.songRepo.MarkSongEnriched(ctx, song.ID, fetchedAt); err != nil {
+		return err
+	}
+
+	if len(updated) == 0 {
+		return nil
+	}
+	return s.audit.RecordSongChange(ctx, entities.AuditActionSongRefresh, song.ID, &before, song, map[string]any{
+		"fields": updated,
+	})
+}
+
+// GetChangeProposals возвращает предложенные изменения с пагинацией.
+func (s *SongServiceImpl) GetChangeProposals(ctx context.Context, status string, limit, offset int) (_ []entities.SongChangeProposal, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.GetChangeProposals")
+	defer tracing.End(span, &err)
+
+	if limit <= 0 {
+		err := fmt.Errorf("%w: limit must be greater than 0", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid limit", "error", err)
+		return nil, err
+	}
+	if offset < 0 {
+		err := fmt.Errorf("%w: offset cannot be negative", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid offset", "error", err)
+		return nil, err
+	}
+
+	switch status {
+	case "", entities.ProposalStatusPending, entities.ProposalStatusAccepted, entities.ProposalStatusRejected:
+	default:
+		err := fmt.Errorf("%w: unknown proposal status %q", ErrInvalidSongInput, status)
+		s.log(ctx).Error("invalid status filter", "error", err)
+		return nil, err
+	}
+
+	return s.changeRepo.GetProposals(ctx, status, limit, offset)
+}
+
+// AcceptChangeProposal применяет предложенное значение к песне.
+func (s *SongServiceImpl) AcceptChangeProposal(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.AcceptChangeProposal", attribute.Int("proposal.id", id))
+	defer tracing.End(span, &err)
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		proposal, err := s.getPendingProposal(ctx, id)
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.Int("song.id", proposal.SongID))
+
+		before, err := s.songRepo.GetSongByID(ctx, proposal.SongID)
+		if err != nil {
+			s.log(ctx).Error("error getting song by ID", "id", proposal.SongID, "error", err)
+			return err
+		}
+		if before == nil {
+			return persistence.ErrSongNotFound
+		}
+
+		if err := s.songRepo.UpdateSongField(ctx, proposal.SongID, proposal.Field, proposal.ProposedValue); err != nil {
+			s.log(ctx).Error("error applying change proposal", "proposalID", id, "error", err)
+			return err
+		}
+
+		// Принятое значение снова считается внешним и дальше обновляется автоматически.
+		if err := s.recordUpstreamProvenance(ctx, proposal.SongID, []string{proposal.Field}, proposal.CreatedAt); err != nil {
+			return err
+		}
+
+		if err := s.resolveProposal(ctx, id, entities.ProposalStatusAccepted); err != nil {
+			return err
+		}
+
+		after := *before
+		entities.SetSongFieldValue(&after, proposal.Field, proposal.ProposedValue)
+		return s.audit.RecordSongChange(ctx, entities.AuditActionSongAccept, proposal.SongID, before, &after, map[string]any{
+			"proposal_id": id,
+			"field":       proposal.Field,
+		})
+	})
+}
+
+// RejectChangeProposal отклоняет предложенное изменение, оставляя песню без изменений.
+func (s *SongServiceImpl) RejectChangeProposal(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongService.RejectChangeProposal", attribute.Int("proposal.id", id))
+	defer tracing.End(span, &err)
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		proposal, err := s.getPendingProposal(ctx, id)
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.Int("song.id", proposal.SongID))
+
+		if err := s.resolveProposal(ctx, id, entities.ProposalStatusRejected); err != nil {
+			return err
+		}
+
+		return s.audit.RecordSongChange(ctx, entities.AuditActionSongReject, proposal.SongID, nil, nil, map[string]any{
+			"proposal_id": id,
+			"field":       proposal.Field,
+		})
+	})
+}
+
+func (s *SongServiceImpl) getPendingProposal(ctx context.Context, id int) (*entities.SongChangeProposal, error) {
+	if id <= 0 {
+		err := fmt.Errorf("%w: invalid proposal ID", ErrInvalidSongInput)
+		s.log(ctx).Error("invalid proposal ID", "error", err)
+		return nil, err
+	}
+
+	proposal, err := s.changeRepo.GetProposalByID(ctx, id)
+	if err != nil {
+		s.log(ctx).Error("error getting change proposal", "id", id, "error", err)
+		return nil, err
+	}
+	if proposal == nil {
+		s.log(ctx).Warn("change proposal not found", "id", id)
+		return nil, ErrProposalNotFound
+	}
+	if proposal.Status != entities.ProposalStatusPending {
+		s.log(ctx).Warn("change proposal already resolved", "id", id, "status", proposal.Status)
+		return nil, ErrProposalResolved
+	}
+
+	return proposal, nil
+}
+
+// resolveProposal закрывает предложение. Если его успели разрешить параллельно,
+// возвращается ErrProposalResolved, и уже сделанные в транзакции изменения откатываются.
+func (s *SongServiceImpl) resolveProposal(ctx context.Context, id int, status string) error {
+	err := s.changeRepo.ResolveProposal(ctx, id, status)
+	if errors.Is(err, persistence.ErrProposalNotPending) {
+		s.log(ctx).Warn("change proposal resolved concurrently", "id", id, "status", status)
+		return ErrProposalResolved
+	}
+	return err
+}
+
+func (s *SongServiceImpl) recordUpstreamProvenance(ctx context.Context, songID int, fields []string, fetchedAt time.Time) error {
+	for _, field := range fields {
+		err := s.songRepo.SaveFieldProvenance(ctx, songID, field, entities.FieldProvenance{
+			Source:    external_api.ProviderName,
+			FetchedAt: &fetchedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isOwnerOrAdmin сообщает, что клиент из контекста — владелец песни или администратор.
+// Без клиента в контексте доступ запрещён: владельца не с кем сравнить.
+func isOwnerOrAdmin(ctx context.Context, song *entities.Song) bool {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	if principal.Type != auth.PrincipalUser || song.OwnerID == nil {
+		return false
+	}
+	return principal.UserID == strconv.Itoa(*song.OwnerID)
+}
+
+func detailFieldValue(details *external_api.SongDetail, field string) string {
+	switch field {
+	case entities.SongFieldReleaseDate:
+		return details.ReleaseDate
+	case entities.SongFieldText:
+		return details.Text
+	case entities.SongFieldLink:
+		return details.Link
+	}
+	return ""
+}
+
 func validateSong(song *entities.Song) error {
 	if song.Group == "" {
-		return errors.New("group cannot be empty")
+		return fmt.Errorf("%w: group cannot be empty", ErrInvalidSongInput)
 	}
 	if song.Song == "" {
-		return errors.New("song name cannot be empty")
+		return fmt.Errorf("%w: song name cannot be empty", ErrInvalidSongInput)
 	}
 	if !isValidReleaseDate(song.ReleaseDate) {
-		return errors.New("invalid release date format")
+		return fmt.Errorf("%w: invalid release date format", ErrInvalidSongInput)
 	}
 	if song.Text == "" {
-		return errors.New("song text cannot be empty")
+		return fmt.Errorf("%w: song text cannot be empty", ErrInvalidSongInput)
 	}
 	if !isValidURL(song.Link) {
-		return errors.New("invalid song link URL")
+		return fmt.Errorf("%w: invalid song link URL", ErrInvalidSongInput)
+	}
+	switch song.Visibility {
+	case entities.VisibilityPrivate, entities.VisibilityShared, entities.VisibilityPublic:
+	default:
+		return fmt.Errorf("%w: unknown visibility %q", ErrInvalidSongInput, song.Visibility)
 	}
 	return nil
 }
@@ -166,3 +693,7 @@ func isValidURL(rawURL string) bool {
 	}
 	return true
 }
+
+func (s *SongServiceImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, s.logger)
+}
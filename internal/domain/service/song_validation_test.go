@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"errors"
+	"testing"
+)
+
+func validSong() entities.Song {
+	return entities.Song{
+		Group:       "Muse",
+		Song:        "Hysteria",
+		ReleaseDate: "01.12.2003",
+		Text:        "It's bugging me",
+		Link:        "https://example.com/hysteria",
+		Visibility:  entities.VisibilityPublic,
+	}
+}
+
+func TestValidateSong(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *entities.Song)
+		valid  bool
+	}{
+		{name: "valid", modify: func(*entities.Song) {}, valid: true},
+		{name: "empty group", modify: func(s *entities.Song) { s.Group = "" }},
+		{name: "empty title", modify: func(s *entities.Song) { s.Song = "" }},
+		{name: "unparseable release date", modify: func(s *entities.Song) { s.ReleaseDate = "2003" }},
+		{name: "empty text", modify: func(s *entities.Song) { s.Text = "" }},
+		{name: "invalid link", modify: func(s *entities.Song) { s.Link = "not a url" }},
+		{name: "unknown visibility", modify: func(s *entities.Song) { s.Visibility = "hidden" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song := validSong()
+			tt.modify(&song)
+
+			err := validateSong(&song)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidSongInput) {
+				t.Fatalf("got %v, want ErrInvalidSongInput", err)
+			}
+		})
+	}
+}
+
+func TestSongServiceRejectsInvalidParameters(t *testing.T) {
+	s := NewSongService(nil, nil, &fakeTx{}, &fakeAudit{}, nil, discardLogger(), nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "songs: zero limit", call: func() error { _, err := s.GetSongs(ctx, nil, 0, 0); return err }},
+		{name: "songs: negative offset", call: func() error { _, err := s.GetSongs(ctx, nil, 10, -1); return err }},
+		{name: "songs: empty group filter", call: func() error {
+			_, err := s.GetSongs(ctx, map[string]interface{}{"group": ""}, 10, 0)
+			return err
+		}},
+		{name: "song by id: zero id", call: func() error { _, err := s.GetSongByID(ctx, 0); return err }},
+		{name: "update: zero id", call: func() error { song := validSong(); return s.UpdateSong(ctx, 0, &song) }},
+		{name: "delete: zero id", call: func() error { return s.DeleteSong(ctx, 0) }},
+		{name: "proposals: unknown status", call: func() error { _, err := s.GetChangeProposals(ctx, "archived", 10, 0); return err }},
+		{name: "proposals: zero limit", call: func() error { _, err := s.GetChangeProposals(ctx, "", 0, 0); return err }},
+		{name: "accept: zero id", call: func() error { return s.AcceptChangeProposal(ctx, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrInvalidSongInput) {
+				t.Fatalf("got %v, want ErrInvalidSongInput", err)
+			}
+		})
+	}
+}
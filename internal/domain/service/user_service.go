@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidUserInput   = errors.New("invalid user input")
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength — bcrypt учитывает только первые 72 байта пароля.
+	maxPasswordLength = 72
+	maxUsernameLength = 255
+)
+
+// dummyPasswordHash используется при входе несуществующего пользователя,
+// чтобы время ответа не выдавало, зарегистрировано ли имя.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// TokenIssuer выпускает токены доступа для пользователей.
+type TokenIssuer interface {
+	IssueToken(user *entities.User) (string, time.Time, error)
+}
+
+type UserService interface {
+	Register(ctx context.Context, username, password string) (*entities.User, error)
+	Login(ctx context.Context, username, password string) (*entities.TokenResponse, error)
+	SetUserRole(ctx context.Context, id int, role string) error
+}
+
+type UserServiceImpl struct {
+	userRepo persistence.UserRepository
+	issuer   TokenIssuer
+	tx       persistence.TxManager
+	audit    AuditService
+	logger   *slog.Logger
+}
+
+func NewUserService(userRepo persistence.UserRepository, issuer TokenIssuer, tx persistence.TxManager, audit AuditService, logger *slog.Logger) *UserServiceImpl {
+	return &UserServiceImpl{
+		userRepo: userRepo,
+		issuer:   issuer,
+		tx:       tx,
+		audit:    audit,
+		logger:   logger.With("service", "UserService"),
+	}
+}
+
+// Register создаёт пользователя с ролью viewer.
+func (s *UserServiceImpl) Register(ctx context.Context, username, password string) (*entities.User, error) {
+	if username == "" || len(username) > maxUsernameLength {
+		err := fmt.Errorf("%w: username must be between 1 and %d characters", ErrInvalidUserInput, maxUsernameLength)
+		s.log(ctx).Error("invalid username", "error", err)
+		return nil, err
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		err := fmt.Errorf("%w: password must be between %d and %d bytes", ErrInvalidUserInput, minPasswordLength, maxPasswordLength)
+		s.log(ctx).Error("invalid password", "error", err)
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		s.log(ctx).Error("error hashing password", "error", err)
+		return nil, err
+	}
+
+	user := &entities.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         auth.RoleViewer,
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("user registered", "userID", user.ID)
+	return user, nil
+}
+
+// Login проверяет пароль и выпускает токен доступа.
+func (s *UserServiceImpl) Login(ctx context.Context, username, password string) (*entities.TokenResponse, error) {
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := dummyPasswordHash
+	if user != nil {
+		hash = []byte(user.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || user == nil {
+		s.log(ctx).Warn("failed login attempt", "username", username)
+		return nil, ErrInvalidCredentials
+	}
+
+	token, expiresAt, err := s.issuer.IssueToken(user)
+	if err != nil {
+		s.log(ctx).Error("error issuing token", "userID", user.ID, "error", err)
+		return nil, err
+	}
+
+	return &entities.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// SetUserRole меняет роль пользователя. Новая роль действует для токенов, выпущенных после изменения.
+func (s *UserServiceImpl) SetUserRole(ctx context.Context, id int, role string) error {
+	if !auth.IsValidRole(role) {
+		err := fmt.Errorf("%w: unknown role %q", ErrInvalidUserInput, role)
+		s.log(ctx).Error("invalid role", "error", err)
+		return err
+	}
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		if err := s.userRepo.SetUserRole(ctx, id, role); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, entities.AuditActionUserSetRole, entities.AuditEntityUser, strconv.Itoa(id), map[string]any{
+			"old_role": user.Role,
+			"new_role": role,
+		})
+	})
+}
+
+func (s *UserServiceImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, s.logger)
+}
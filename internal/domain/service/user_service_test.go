@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserRepo хранит пользователей в памяти.
+type fakeUserRepo struct {
+	users          map[int]*entities.User
+	lookedUpByName []string
+}
+
+func newFakeUserRepo(users ...*entities.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[int]*entities.User{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) CreateUser(_ context.Context, user *entities.User) error {
+	for _, u := range r.users {
+		if u.Username == user.Username {
+			return persistence.ErrUsernameTaken
+		}
+	}
+	user.ID = len(r.users) + 1
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetUserByUsername(_ context.Context, username string) (*entities.User, error) {
+	r.lookedUpByName = append(r.lookedUpByName, username)
+	for _, u := range r.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetUserByID(_ context.Context, id int) (*entities.User, error) {
+	return r.users[id], nil
+}
+
+func (r *fakeUserRepo) SetUserRole(_ context.Context, id int, role string) error {
+	r.users[id].Role = role
+	return nil
+}
+
+type fakeIssuer struct{}
+
+func (fakeIssuer) IssueToken(user *entities.User) (string, time.Time, error) {
+	return "token-for-" + user.Username, time.Now().Add(time.Hour), nil
+}
+
+func newTestUserService(repo *fakeUserRepo) (*UserServiceImpl, *fakeAudit) {
+	audit := &fakeAudit{}
+	return NewUserService(repo, fakeIssuer{}, &fakeTx{}, audit, discardLogger()), audit
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", username: "alice", password: "correct horse"},
+		{name: "empty username", username: "", password: "correct horse", wantErr: ErrInvalidUserInput},
+		{name: "username too long", username: strings.Repeat("a", maxUsernameLength+1), password: "correct horse", wantErr: ErrInvalidUserInput},
+		{name: "password too short", username: "alice", password: "short", wantErr: ErrInvalidUserInput},
+		{name: "password longer than bcrypt accepts", username: "alice", password: strings.Repeat("p", maxPasswordLength+1), wantErr: ErrInvalidUserInput},
+		{name: "username taken", username: "bob", password: "correct horse", wantErr: persistence.ErrUsernameTaken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeUserRepo(&entities.User{ID: 1, Username: "bob"})
+			s, _ := newTestUserService(repo)
+
+			user, err := s.Register(context.Background(), tt.username, tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if user.Role != auth.RoleViewer {
+				t.Errorf("role = %q, want %q", user.Role, auth.RoleViewer)
+			}
+			if user.PasswordHash == tt.password {
+				t.Fatal("password must not be stored in plain text")
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(tt.password)); err != nil {
+				t.Errorf("stored hash does not match the password: %v", err)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newFakeUserRepo(&entities.User{ID: 1, Username: "alice", PasswordHash: string(hash), Role: auth.RoleEditor})
+	s, _ := newTestUserService(repo)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "valid credentials", username: "alice", password: "correct horse"},
+		{name: "wrong password", username: "alice", password: "wrong horse", wantErr: ErrInvalidCredentials},
+		{name: "unknown user", username: "mallory", password: "correct horse", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.Login(context.Background(), tt.username, tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if token.AccessToken != "token-for-alice" || token.TokenType != "Bearer" {
+				t.Errorf("unexpected token %+v", token)
+			}
+		})
+	}
+}
+
+// Для неизвестного имени пароль сверяется с dummyPasswordHash; чтобы по времени ответа нельзя было
+// узнать, зарегистрировано ли имя, это должен быть настоящий bcrypt-хэш той же стоимости, что у пользователей.
+func TestLoginUnknownUserComparesDummyHash(t *testing.T) {
+	cost, err := bcrypt.Cost(dummyPasswordHash)
+	if err != nil {
+		t.Fatalf("dummy hash is not a bcrypt hash: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d, want %d (the cost used by Register)", cost, bcrypt.DefaultCost)
+	}
+
+	repo := newFakeUserRepo()
+	s, _ := newTestUserService(repo)
+
+	started := time.Now()
+	if _, err := s.Login(context.Background(), "mallory", "dummy-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("the dummy password must not log anyone in, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < time.Millisecond {
+		t.Errorf("login of an unknown user took %s, the password was not compared", elapsed)
+	}
+	if !slices.Equal(repo.lookedUpByName, []string{"mallory"}) {
+		t.Errorf("looked up %v", repo.lookedUpByName)
+	}
+}
+
+func TestSetUserRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		role    string
+		wantErr error
+	}{
+		{name: "valid role", id: 1, role: auth.RoleEditor},
+		{name: "unknown role", id: 1, role: "superuser", wantErr: ErrInvalidUserInput},
+		{name: "unknown user", id: 2, role: auth.RoleEditor, wantErr: ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeUserRepo(&entities.User{ID: 1, Username: "alice", Role: auth.RoleViewer})
+			s, audit := newTestUserService(repo)
+
+			err := s.SetUserRole(context.Background(), tt.id, tt.role)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				if repo.users[1].Role != auth.RoleViewer || len(audit.actions) != 0 {
+					t.Error("role must not change on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.users[tt.id].Role != tt.role {
+				t.Errorf("role = %q, want %q", repo.users[tt.id].Role, tt.role)
+			}
+			if !slices.Equal(audit.actions, []string{entities.AuditActionUserSetRole}) {
+				t.Errorf("audit actions = %v", audit.actions)
+			}
+		})
+	}
+}
@@ -3,50 +3,159 @@ package external_api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"effictiveMobile/internal/infrastrtucture/tracing"
 	"effictiveMobile/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
+// ProviderName записывается как источник данных, полученных через этот клиент.
+const ProviderName = "external_api"
+
+const tracerName = "effictiveMobile/external_api"
+
 type SongDetail struct {
 	ReleaseDate string `json:"releaseDate"`
 	Text        string `json:"text"`
 	Link        string `json:"link"`
 }
 
+// Observer получает исход и длительность каждого обращения к внешнему API.
+type Observer func(outcome string, duration time.Duration)
+
+// Client читает адрес и таймаут из актуального снимка конфигурации на каждый запрос,
+// поэтому они меняются при перезагрузке конфига без пересоздания клиента.
 type Client struct {
 	httpClient *http.Client
-	baseURL    string
+	config     *config.Store
+
+	// limiter ограничивает частоту запросов (token bucket), slots — число одновременных запросов.
+	limiter *rate.Limiter
+	slots   chan struct{}
+	breaker *circuitBreaker
+
+	observe Observer
 }
 
-func NewClient() *Client {
+// NewClient создаёт клиент внешнего API; observe может быть nil.
+func NewClient(cfg *config.Store, observe Observer) *Client {
+	current := cfg.Current()
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		baseURL: config.Config.ExternalApiUrl(),
+		httpClient: &http.Client{},
+		config:     cfg,
+		limiter:    rate.NewLimiter(rate.Limit(current.ExternalRateLimit()), current.ExternalBurst()),
+		slots:      make(chan struct{}, current.ExternalMaxConcurrency()),
+		breaker:    newCircuitBreaker(current.ExternalCircuitFailures(), current.ExternalCircuitCooldown()),
+		observe:    observe,
+	}
+}
+
+// SetRateLimit меняет частоту запросов и размер всплеска; уже ожидающие вызовы подхватят новые значения.
+func (c *Client) SetRateLimit(limit float64, burst int) {
+	c.limiter.SetLimit(rate.Limit(limit))
+	c.limiter.SetBurst(burst)
+}
+
+// CircuitState возвращает состояние предохранителя внешнего API.
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.State()
+}
+
+// acquire ждёт токен, а затем свободный слот для запроса. Токен берётся первым, чтобы вызовы,
+// ждущие лимита, не держали слоты. Ожидание прерывается при отмене ctx, в этом случае слот не занимается.
+func (c *Client) acquire(ctx context.Context) (release func(), err error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, limiterError(ctx, err)
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return func() { <-c.slots }, nil
+}
+
+// limiterError приводит ошибку rate.Limiter к context.DeadlineExceeded, если токен не успеет
+// появиться до дедлайна: сам лимитер в этом случае возвращает собственную ошибку без обёртки.
+func limiterError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// GetSongDetails выполняет запрос к внешнему API для получения деталей о песне.
+// Спан охватывает и ожидание лимита, и сам HTTP-запрос; исход пишется в атрибут outcome.
+func (c *Client) GetSongDetails(ctx context.Context, group, song string) (_ *SongDetail, err error) {
+	ctx, span := tracing.StartClient(ctx, tracerName, "GET /info",
+		attribute.String("song.group", group), attribute.String("song.title", song))
+	defer func() {
+		span.SetAttributes(attribute.String("outcome", outcome(err)))
+		tracing.End(span, &err)
+	}()
+
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for external API rate limit: %w", err)
+	}
+	defer release()
+
+	started := time.Now()
+	if err := c.breaker.allow(); err != nil {
+		c.report(err, started)
+		return nil, err
 	}
+
+	detail, err := c.fetchSongDetails(ctx, group, song)
+	if errors.Is(err, context.Canceled) {
+		c.breaker.cancel()
+	} else {
+		c.breaker.record(isUpstreamFailure(err))
+	}
+	c.report(err, started)
+	return detail, err
 }
 
-// GetSongDetails выполняет запрос к внешнему API для получения деталей о песне
-func (c *Client) GetSongDetails(ctx context.Context, group, song string) (*SongDetail, error) {
-	url := fmt.Sprintf("%s/info?group=%s&song=%s", c.baseURL, group, song)
+func (c *Client) report(err error, started time.Time) {
+	if c.observe != nil {
+		c.observe(outcome(err), time.Since(started))
+	}
+}
+
+func (c *Client) fetchSongDetails(ctx context.Context, group, song string) (*SongDetail, error) {
+	cfg := c.config.Current()
+	ctx, cancel := context.WithTimeout(ctx, cfg.ExternalTimeout())
+	defer cancel()
+
+	url := fmt.Sprintf("%s/info?group=%s&song=%s", cfg.ExternalApiUrl(), group, song)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get song details: %s", resp.Status)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	var detail SongDetail
@@ -56,3 +165,44 @@ func (c *Client) GetSongDetails(ctx context.Context, group, song string) (*SongD
 
 	return &detail, nil
 }
+
+// StatusError — ответ внешнего API с кодом, отличным от 200.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "failed to get song details: " + e.Status
+}
+
+// outcome сводит результат вызова к небольшому набору значений для метрик.
+func outcome(err error) string {
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &statusErr) && !isUpstreamFailure(err):
+		return "client_error"
+	}
+	return "error"
+}
+
+// isUpstreamFailure отделяет сбои внешнего API от ответов о том, что песня не найдена или запрос некорректен.
+// Отмена запроса вызывающей стороной сбоем не считается.
+func isUpstreamFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
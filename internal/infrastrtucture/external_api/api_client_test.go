@@ -0,0 +1,68 @@
+package external_api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func newTestClient(limit rate.Limit, burst, concurrency int) *Client {
+	return &Client{
+		limiter: rate.NewLimiter(limit, burst),
+		slots:   make(chan struct{}, concurrency),
+		breaker: newCircuitBreaker(3, time.Minute),
+	}
+}
+
+func TestAcquireReportsDeadlineWhenRateLimited(t *testing.T) {
+	c := newTestClient(rate.Every(time.Hour), 1, 1)
+
+	release, err := c.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first call must get the burst token: %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if got := outcome(err); got != "timeout" {
+		t.Errorf("outcome = %q, want timeout", got)
+	}
+	if len(c.slots) != 0 {
+		t.Error("slot must not be held after a failed wait")
+	}
+}
+
+func TestAcquireDoesNotHoldSlotWhileWaitingForToken(t *testing.T) {
+	c := newTestClient(rate.Every(time.Hour), 1, 1)
+	release, err := c.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.acquire(ctx)
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if len(c.slots) != 0 {
+		t.Error("caller waiting for a token must not hold a slot")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,141 @@
+package external_api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = time.Minute
+
+	type step struct {
+		advance time.Duration // сдвиг часов перед шагом
+		call    string        // "success", "failure", "cancel" или "" — только проверка состояния
+		allowed bool          // ожидаемый ответ allow(), если call не пуст
+		state   CircuitState  // ожидаемое состояние после шага
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "stays closed below threshold",
+			steps: []step{
+				{call: "failure", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitClosed},
+				{call: "success", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitClosed},
+			},
+		},
+		{
+			name: "opens after threshold and rejects during cooldown",
+			steps: []step{
+				{call: "failure", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitOpen},
+				{advance: cooldown / 2, call: "success", allowed: false, state: CircuitOpen},
+			},
+		},
+		{
+			name: "successful probe closes the circuit",
+			steps: []step{
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true, state: CircuitOpen},
+				{advance: cooldown, state: CircuitHalfOpen},
+				{call: "success", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitClosed},
+			},
+		},
+		{
+			name: "failed probe opens the circuit again",
+			steps: []step{
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true, state: CircuitOpen},
+				{advance: cooldown, call: "failure", allowed: true, state: CircuitOpen},
+				{advance: cooldown / 2, call: "success", allowed: false, state: CircuitOpen},
+				{advance: cooldown / 2, call: "success", allowed: true, state: CircuitClosed},
+			},
+		},
+		{
+			name: "cancelled probe is not counted as success",
+			steps: []step{
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true, state: CircuitOpen},
+				{advance: cooldown, call: "cancel", allowed: true, state: CircuitHalfOpen},
+				{call: "failure", allowed: true, state: CircuitOpen},
+			},
+		},
+		{
+			name: "cancelled call in closed state keeps the failure count",
+			steps: []step{
+				{call: "failure", allowed: true},
+				{call: "failure", allowed: true},
+				{call: "cancel", allowed: true, state: CircuitClosed},
+				{call: "failure", allowed: true, state: CircuitOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			b := newCircuitBreaker(3, cooldown)
+			b.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+
+				if s.call != "" {
+					err := b.allow()
+					if allowed := err == nil; allowed != s.allowed {
+						t.Fatalf("step %d: allow() = %v, want allowed=%v", i, err, s.allowed)
+					}
+					if err != nil && !errors.Is(err, ErrCircuitOpen) {
+						t.Fatalf("step %d: got %v, want ErrCircuitOpen", i, err)
+					}
+					if err == nil {
+						switch s.call {
+						case "success":
+							b.record(false)
+						case "failure":
+							b.record(true)
+						case "cancel":
+							b.cancel()
+						}
+					}
+				}
+
+				if s.state != "" {
+					if got := b.State(); got != s.state {
+						t.Fatalf("step %d: state = %s, want %s", i, got, s.state)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerAllowsSingleProbe(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	if err := b.allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.record(true)
+
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe must be allowed after cooldown: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call during the probe: got %v, want ErrCircuitOpen", err)
+	}
+}
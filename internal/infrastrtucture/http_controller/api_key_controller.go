@@ -0,0 +1,242 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRotationOverlap = 24 * time.Hour
+
+type ApiKeyController struct {
+	apiKeyService service.ApiKeyService
+	logger        *slog.Logger
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService, logger *slog.Logger) *ApiKeyController {
+	return &ApiKeyController{
+		apiKeyService: apiKeyService,
+		logger:        logger.With("controller", "ApiKeyController"),
+	}
+}
+
+// GetApiKeysHandler
+// @Title List API keys
+// @Description Retrieve API keys with their metadata; secrets are never returned
+// @Tag Admin
+// @Param  limit   query  int  true   "Number of keys to return"   "10"
+// @Param  offset  query  int  true   "Offset for pagination"      "0"
+// @Success  200  array   []entities.ApiKey  "API keys"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input parameters"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/keys [get]
+func (c *ApiKeyController) GetApiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		c.log(ctx).Error("invalid limit parameter", "limit", limitStr, "error", err)
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		c.log(ctx).Error("invalid offset parameter", "offset", offsetStr, "error", err)
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	keys, err := c.apiKeyService.GetApiKeys(ctx, limit, offset)
+	if err != nil {
+		c.log(ctx).Error("failed to retrieve api keys", "error", err)
+		http.Error(w, "Failed to retrieve api keys: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// CreateApiKeyHandler
+// @Title Create API key
+// @Description Issue a new API key; the secret is returned only in this response
+// @Tag Admin
+// @Param key body entities.CreateApiKeyRequest true "Name, scopes and optional expiration of the key"
+// @Success  201  object  entities.IssuedApiKeyResponse  "Issued key"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/keys/create [post]
+func (c *ApiKeyController) CreateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.CreateApiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || len(req.Scopes) == 0 {
+		http.Error(w, "Name and scopes are required", http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := c.apiKeyService.CreateApiKey(ctx, req.Name, req.Scopes, req.ExpiresAt)
+	switch {
+	case errors.Is(err, service.ErrInvalidApiKeyInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to create api key", "name", req.Name, "error", err)
+		http.Error(w, "Failed to create api key: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	c.writeIssuedKey(w, r, key, secret)
+}
+
+// RotateApiKeyHandler
+// @Title Rotate API key
+// @Description Issue a replacement key; the old key keeps working during the overlap period
+// @Tag Admin
+// @Param  id    path  int                           true   "ID of the key to rotate"  "1"
+// @Param  body  body  entities.RotateApiKeyRequest  false  "Overlap period"
+// @Success  201  object  entities.IssuedApiKeyResponse  "Replacement key"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "Key not found"
+// @Failure  409  object  entities.ErrorResponse   "Key is revoked or expired"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/keys/rotate/{id} [post]
+func (c *ApiKeyController) RotateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := c.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var req entities.RotateApiKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	overlap := defaultRotationOverlap
+	if req.Overlap != "" {
+		parsed, err := time.ParseDuration(req.Overlap)
+		if err != nil {
+			http.Error(w, "Invalid overlap duration", http.StatusBadRequest)
+			return
+		}
+		overlap = parsed
+	}
+
+	key, secret, err := c.apiKeyService.RotateApiKey(ctx, id, overlap)
+	if err != nil {
+		c.writeKeyError(w, r, id, err, "Failed to rotate api key")
+		return
+	}
+
+	c.writeIssuedKey(w, r, key, secret)
+}
+
+// RevokeApiKeyHandler
+// @Title Revoke API key
+// @Description Revoke an API key immediately
+// @Tag Admin
+// @Param  id  path  int  true  "ID of the key to revoke"  "1"
+// @Success  200  object  map[string]string  "Key revoked"
+// @Failure  400  object  entities.ErrorResponse   "Invalid key ID"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "Key not found"
+// @Failure  409  object  entities.ErrorResponse   "Key is already revoked"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/keys/revoke/{id} [delete]
+func (c *ApiKeyController) RevokeApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := c.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.apiKeyService.RevokeApiKey(ctx, id); err != nil {
+		c.writeKeyError(w, r, id, err, "Failed to revoke api key")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Key revoked"}); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (c *ApiKeyController) parseKeyID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.log(r.Context()).Error("invalid api key ID", "id", idStr, "error", err)
+		http.Error(w, "Invalid key ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (c *ApiKeyController) writeKeyError(w http.ResponseWriter, r *http.Request, id int, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrApiKeyNotFound):
+		http.Error(w, "Key not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrApiKeyRevoked):
+		http.Error(w, "Key is revoked", http.StatusConflict)
+	case errors.Is(err, service.ErrApiKeyExpired):
+		http.Error(w, "Key is expired, issue a new one instead", http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidApiKeyInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		c.log(r.Context()).Error(message, "keyID", id, "error", err)
+		http.Error(w, message+": "+err.Error(), errorStatus(err))
+	}
+}
+
+func (c *ApiKeyController) writeIssuedKey(w http.ResponseWriter, r *http.Request, key *entities.ApiKey, secret string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(entities.IssuedApiKeyResponse{Key: *key, Secret: secret}); err != nil {
+		c.log(r.Context()).Error("failed to encode response", "error", err)
+	}
+}
+
+func (c *ApiKeyController) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, c.logger)
+}
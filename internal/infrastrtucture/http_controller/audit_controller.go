@@ -0,0 +1,119 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditController struct {
+	auditService service.AuditService
+	logger       *slog.Logger
+}
+
+func NewAuditController(auditService service.AuditService, logger *slog.Logger) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+		logger:       logger.With("controller", "AuditController"),
+	}
+}
+
+// GetAuditEntriesHandler
+// @Title List audit entries
+// @Description Retrieve audit log entries, newest first, with optional filters
+// @Tag Admin
+// @Param  limit        query  int     true   "Number of entries to return"                "10"
+// @Param  offset       query  int     true   "Offset for pagination"                      "0"
+// @Param  actor_type   query  string  false  "Filter by actor type"                       "api_key"
+// @Param  actor_id     query  string  false  "Filter by actor ID"                         "3"
+// @Param  action       query  string  false  "Filter by action"                           "song.update"
+// @Param  entity_type  query  string  false  "Filter by entity type"                      "song"
+// @Param  entity_id    query  string  false  "Filter by entity ID"                        "4"
+// @Param  song_id      query  int     false  "Shortcut for entity_type=song&entity_id=N"  "4"
+// @Param  from         query  string  false  "Entries created at or after (RFC 3339)"     "2024-10-01T00:00:00Z"
+// @Param  to           query  string  false  "Entries created before (RFC 3339)"          "2024-11-01T00:00:00Z"
+// @Success  200  array   []entities.AuditEntry    "Audit entries"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input parameters"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/audit [get]
+func (c *AuditController) GetAuditEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	limitStr := query.Get("limit")
+	offsetStr := query.Get("offset")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		c.log(ctx).Error("invalid limit parameter", "limit", limitStr, "error", err)
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		c.log(ctx).Error("invalid offset parameter", "offset", offsetStr, "error", err)
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	filter := entities.AuditFilter{
+		ActorType:  query.Get("actor_type"),
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+	}
+	if songIDStr := query.Get("song_id"); songIDStr != "" {
+		songID, err := strconv.Atoi(songIDStr)
+		if err != nil || songID <= 0 {
+			http.Error(w, "Invalid song_id parameter", http.StatusBadRequest)
+			return
+		}
+		filter.EntityType = entities.AuditEntitySong
+		filter.EntityID = strconv.Itoa(songID)
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid "+name+" parameter", http.StatusBadRequest)
+			return
+		}
+		*target = &parsed
+	}
+
+	entries, err := c.auditService.GetAuditEntries(ctx, filter, limit, offset)
+	if errors.Is(err, service.ErrInvalidAuditFilter) {
+		http.Error(w, "Parameter from must be before to", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.log(ctx).Error("failed to retrieve audit entries", "error", err)
+		http.Error(w, "Failed to retrieve audit entries: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (c *AuditController) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, c.logger)
+}
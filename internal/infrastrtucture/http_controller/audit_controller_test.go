@@ -0,0 +1,131 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/domain/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubAuditService запоминает фильтр последнего запроса.
+type stubAuditService struct {
+	service.AuditService
+	entries []entities.AuditEntry
+	err     error
+	filter  *entities.AuditFilter
+}
+
+func (s *stubAuditService) GetAuditEntries(_ context.Context, filter entities.AuditFilter, _, _ int) ([]entities.AuditEntry, error) {
+	s.filter = &filter
+	return s.entries, s.err
+}
+
+func TestAuditControllerFilters(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		want   int
+		filter *entities.AuditFilter
+	}{
+		{name: "missing limit", query: "offset=0", want: http.StatusBadRequest},
+		{name: "negative offset", query: "limit=10&offset=-1", want: http.StatusBadRequest},
+		{name: "invalid song id", query: "limit=10&offset=0&song_id=abc", want: http.StatusBadRequest},
+		{name: "invalid from", query: "limit=10&offset=0&from=yesterday", want: http.StatusBadRequest},
+		{
+			name:   "actor filter",
+			query:  "limit=10&offset=0&actor_type=api_key&actor_id=3&action=song.update",
+			want:   http.StatusOK,
+			filter: &entities.AuditFilter{ActorType: "api_key", ActorID: "3", Action: "song.update"},
+		},
+		{
+			name:   "song id overrides entity filter",
+			query:  "limit=10&offset=0&entity_type=api_key&entity_id=9&song_id=4",
+			want:   http.StatusOK,
+			filter: &entities.AuditFilter{EntityType: entities.AuditEntitySong, EntityID: "4"},
+		},
+		{
+			name:   "time range",
+			query:  "limit=10&offset=0&from=2024-10-01T00:00:00Z",
+			want:   http.StatusOK,
+			filter: &entities.AuditFilter{From: &from},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &stubAuditService{entries: []entities.AuditEntry{}}
+			c := NewAuditController(audit, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			w := httptest.NewRecorder()
+			c.GetAuditEntriesHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?"+tt.query, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.filter == nil {
+				if audit.filter != nil {
+					t.Error("service must not be called for invalid input")
+				}
+				return
+			}
+			got := *audit.filter
+			if got.ActorType != tt.filter.ActorType || got.ActorID != tt.filter.ActorID || got.Action != tt.filter.Action ||
+				got.EntityType != tt.filter.EntityType || got.EntityID != tt.filter.EntityID {
+				t.Errorf("filter = %+v, want %+v", got, *tt.filter)
+			}
+			if (got.From == nil) != (tt.filter.From == nil) || (got.From != nil && !got.From.Equal(*tt.filter.From)) {
+				t.Errorf("from = %v, want %v", got.From, tt.filter.From)
+			}
+		})
+	}
+}
+
+func TestAuditControllerServiceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "empty time range", err: service.ErrInvalidAuditFilter, want: http.StatusBadRequest},
+		{name: "database failure", err: errors.New("connection reset"), want: http.StatusInternalServerError},
+		{name: "timeout", err: context.DeadlineExceeded, want: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewAuditController(&stubAuditService{err: tt.err}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			w := httptest.NewRecorder()
+			c.GetAuditEntriesHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=10&offset=0", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditControllerReturnsEntries(t *testing.T) {
+	audit := &stubAuditService{entries: []entities.AuditEntry{{ID: 1, Action: "song.create", EntityType: entities.AuditEntitySong, EntityID: "4"}}}
+	c := NewAuditController(audit, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	w := httptest.NewRecorder()
+	c.GetAuditEntriesHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=10&offset=0", nil))
+
+	var got []entities.AuditEntry
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Action != "song.create" || got[0].EntityID != "4" {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/pkg/config"
+	"effictiveMobile/pkg/httpinfo"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest — нестандартный код (как в nginx) для запроса, который клиент отменил,
+// не дождавшись ответа. Сам клиент его уже не получит, но он попадает в access-лог и метрики.
+const StatusClientClosedRequest = 499
+
+type RequestDeadline struct {
+	config *config.Store
+}
+
+func NewRequestDeadline(cfg *config.Store) *RequestDeadline {
+	return &RequestDeadline{
+		config: cfg,
+	}
+}
+
+// Handle ограничивает время обработки запроса, отменяя его контекст: запросы к базе и внешнему API
+// прерываются, а обработчик отвечает 504. Таймаут берётся из актуальной конфигурации:
+// server.route_timeouts для маршрута или server.request_timeout по умолчанию.
+func (d *RequestDeadline) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := d.config.Current().ServerRequestTimeout(r.Method, httpinfo.Route(r))
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// errorStatus выбирает код ответа для ошибки сервиса: 499, если клиент отключился,
+// 504, если истёк дедлайн запроса, и 500 во всех остальных случаях.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,135 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const readinessCheckTimeout = 3 * time.Second
+
+var errDraining = errors.New("server is shutting down")
+
+// DependencyCheck проверяет одну зависимость и возвращает описание её состояния.
+// Некритичная зависимость попадает в отчёт, но не делает сервис неготовым.
+type DependencyCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) (detail string, err error)
+}
+
+type HealthController struct {
+	checks   []DependencyCheck
+	draining atomic.Bool
+	logger   *slog.Logger
+}
+
+func NewHealthController(checks []DependencyCheck, logger *slog.Logger) *HealthController {
+	return &HealthController{
+		checks: checks,
+		logger: logger.With("controller", "HealthController"),
+	}
+}
+
+// SetDraining переводит /readyz в состояние отказа, чтобы балансировщик перестал присылать запросы перед остановкой.
+func (c *HealthController) SetDraining() {
+	c.draining.Store(true)
+}
+
+// LivenessHandler
+// @Title Liveness probe
+// @Description Reports that the process is alive; dependencies are not checked
+// @Tag Health
+// @Success  200  object  entities.HealthResponse  "Process is alive"
+// @Route /healthz [get]
+func (c *HealthController) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	c.writeHealth(w, http.StatusOK, entities.HealthResponse{Status: entities.HealthStatusOK})
+}
+
+// ReadinessHandler
+// @Title Readiness probe
+// @Description Checks the database, the schema version and the external API circuit; fails while shutting down
+// @Tag Health
+// @Success  200  object  entities.HealthResponse  "Ready to serve requests"
+// @Failure  503  object  entities.HealthResponse  "A critical dependency is failing or the server is shutting down"
+// @Route /readyz [get]
+func (c *HealthController) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	response := entities.HealthResponse{
+		Status:       entities.HealthStatusOK,
+		Dependencies: make(map[string]entities.DependencyHealth, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Dependencies[check.Name] = result
+			if result.Status == entities.HealthStatusOK {
+				return
+			}
+			if check.Critical {
+				response.Status = entities.HealthStatusFail
+			} else if response.Status == entities.HealthStatusOK {
+				response.Status = entities.HealthStatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.draining.Load() {
+		response.Status = entities.HealthStatusFail
+		response.Dependencies["server"] = entities.DependencyHealth{
+			Status:   entities.HealthStatusFail,
+			Critical: true,
+			Error:    errDraining.Error(),
+		}
+	}
+
+	status := http.StatusOK
+	if response.Status == entities.HealthStatusFail {
+		status = http.StatusServiceUnavailable
+		c.logger.Warn("service is not ready", "dependencies", response.Dependencies)
+	}
+	c.writeHealth(w, status, response)
+}
+
+func runCheck(ctx context.Context, check DependencyCheck) entities.DependencyHealth {
+	started := time.Now()
+	detail, err := check.Check(ctx)
+
+	result := entities.DependencyHealth{
+		Status:    entities.HealthStatusOK,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		result.Status = entities.HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (c *HealthController) writeHealth(w http.ResponseWriter, status int, response entities.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		c.logger.Error("failed to encode response", "error", err)
+	}
+}
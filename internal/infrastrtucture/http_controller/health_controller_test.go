@@ -0,0 +1,86 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func stubCheck(name string, critical bool, err error) DependencyCheck {
+	return DependencyCheck{
+		Name:     name,
+		Critical: critical,
+		Check:    func(context.Context) (string, error) { return "", err },
+	}
+}
+
+func serveReadiness(t *testing.T, c *HealthController) (int, entities.HealthResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c.ReadinessHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var response entities.HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, response
+}
+
+func TestReadiness(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		checks     []DependencyCheck
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "all dependencies up", checks: []DependencyCheck{stubCheck("database", true, nil), stubCheck("external_api", false, nil)}, wantCode: http.StatusOK, wantStatus: entities.HealthStatusOK},
+		{name: "optional dependency down", checks: []DependencyCheck{stubCheck("database", true, nil), stubCheck("external_api", false, down)}, wantCode: http.StatusOK, wantStatus: entities.HealthStatusDegraded},
+		{name: "critical dependency down", checks: []DependencyCheck{stubCheck("database", true, down), stubCheck("external_api", false, down)}, wantCode: http.StatusServiceUnavailable, wantStatus: entities.HealthStatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHealthController(tt.checks, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			code, response := serveReadiness(t, c)
+			if code != tt.wantCode || response.Status != tt.wantStatus {
+				t.Errorf("got %d %s, want %d %s", code, response.Status, tt.wantCode, tt.wantStatus)
+			}
+			if len(response.Dependencies) != len(tt.checks) {
+				t.Errorf("dependencies = %v, want one entry per check", response.Dependencies)
+			}
+		})
+	}
+}
+
+func TestReadinessFailsWhileDraining(t *testing.T) {
+	c := NewHealthController([]DependencyCheck{stubCheck("database", true, nil)}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if code, _ := serveReadiness(t, c); code != http.StatusOK {
+		t.Fatalf("status before draining = %d, want 200", code)
+	}
+
+	c.SetDraining()
+
+	code, response := serveReadiness(t, c)
+	if code != http.StatusServiceUnavailable || response.Status != entities.HealthStatusFail {
+		t.Errorf("got %d %s, want 503 fail", code, response.Status)
+	}
+	if server := response.Dependencies["server"]; server.Error != errDraining.Error() {
+		t.Errorf("server dependency = %+v, want the draining error", server)
+	}
+
+	w := httptest.NewRecorder()
+	c.LivenessHandler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("liveness while draining = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package http_controller
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/pkg/config"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	hmacScheme               = "HMAC-SHA256"
+	headerSignatureTimestamp = "X-Signature-Timestamp"
+	headerSignatureNonce     = "X-Signature-Nonce"
+	maxSignedBodySize        = 10 << 20
+	maxNonceLength           = 128
+)
+
+var errInvalidSignature = errors.New("invalid request signature")
+
+// HMACAuthenticator проверяет запросы, подписанные общим секретом клиента:
+//
+//	Authorization: HMAC-SHA256 Credential=<client id>, Signature=<hex hmac>
+//	X-Signature-Timestamp: <unix seconds>
+//	X-Signature-Nonce: <random string>
+//
+// Подписывается строка из схемы, метода, пути, query, времени, nonce и SHA-256 тела,
+// разделённых переводом строки (см. stringToSign).
+type HMACAuthenticator struct {
+	clients map[string]config.HMACClient
+	maxSkew time.Duration
+	nonces  *nonceCache
+}
+
+func NewHMACAuthenticator(clients []config.HMACClient, maxSkew time.Duration, nonceCacheSize int) *HMACAuthenticator {
+	byID := make(map[string]config.HMACClient, len(clients))
+	for _, client := range clients {
+		byID[client.ID] = client
+	}
+
+	return &HMACAuthenticator{
+		clients: byID,
+		maxSkew: maxSkew,
+		// Nonce достаточно помнить, пока запрос с ним проходит проверку времени.
+		nonces: newNonceCache(nonceCacheSize, 2*maxSkew),
+	}
+}
+
+// Authenticate проверяет подпись запроса; params — часть заголовка Authorization после схемы.
+// Тело запроса вычитывается для подсчёта хэша и подменяется копией для следующих обработчиков.
+func (h *HMACAuthenticator) Authenticate(r *http.Request, params string) (*auth.Principal, error) {
+	credential, signature, err := parseHMACParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := h.clients[credential]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown credential", errInvalidSignature)
+	}
+
+	timestamp := r.Header.Get(headerSignatureTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", errInvalidSignature)
+	}
+	now := time.Now()
+	if skew := now.Sub(time.Unix(unix, 0)); skew > h.maxSkew || skew < -h.maxSkew {
+		return nil, fmt.Errorf("%w: timestamp outside of allowed skew", errInvalidSignature)
+	}
+
+	nonce := r.Header.Get(headerSignatureNonce)
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return nil, fmt.Errorf("%w: invalid nonce", errInvalidSignature)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSignedBodySize {
+		return nil, fmt.Errorf("%w: body too large", errInvalidSignature)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	mac := hmac.New(sha256.New, []byte(client.Secret))
+	mac.Write([]byte(stringToSign(r, timestamp, nonce, body)))
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return nil, fmt.Errorf("%w: signature mismatch", errInvalidSignature)
+	}
+
+	// Nonce запоминается только для запросов с верной подписью, чтобы кэш нельзя было забить мусором.
+	if err := h.nonces.Add(credential+":"+nonce, now); err != nil {
+		if errors.Is(err, errNonceUsed) {
+			return nil, fmt.Errorf("%w: %w", errInvalidSignature, err)
+		}
+		return nil, err
+	}
+
+	return &auth.Principal{
+		Type:   auth.PrincipalHMAC,
+		ID:     client.ID,
+		Name:   client.ID,
+		Scopes: client.Scopes,
+	}, nil
+}
+
+func stringToSign(r *http.Request, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		hmacScheme,
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.Query().Encode(),
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+func parseHMACParams(params string) (string, []byte, error) {
+	var credential, signature string
+	for _, part := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return "", nil, fmt.Errorf("%w: malformed authorization header", errInvalidSignature)
+		}
+		switch key {
+		case "Credential":
+			credential = value
+		case "Signature":
+			signature = value
+		}
+	}
+	if credential == "" || signature == "" {
+		return "", nil, fmt.Errorf("%w: credential and signature are required", errInvalidSignature)
+	}
+
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: signature is not hex", errInvalidSignature)
+	}
+	return credential, decoded, nil
+}
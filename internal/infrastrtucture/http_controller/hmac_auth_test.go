@@ -0,0 +1,235 @@
+package http_controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/pkg/config"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID = "billing"
+	testSecret   = "s3cr3t"
+	testMaxSkew  = 5 * time.Minute
+)
+
+func newTestHMACAuthenticator() *HMACAuthenticator {
+	return NewHMACAuthenticator([]config.HMACClient{{
+		ID:     testClientID,
+		Secret: testSecret,
+		Scopes: []string{auth.ScopeSongsRead},
+	}}, testMaxSkew, 100)
+}
+
+type signedRequest struct {
+	method    string
+	target    string
+	body      string
+	secret    string
+	timestamp time.Time
+	nonce     string
+}
+
+func defaultSignedRequest() signedRequest {
+	return signedRequest{
+		method:    http.MethodPost,
+		target:    "/api/v1/songs/create?source=import",
+		body:      `{"group":"Muse","song":"Hysteria"}`,
+		secret:    testSecret,
+		timestamp: time.Now(),
+		nonce:     "nonce-1",
+	}
+}
+
+// build подписывает запрос так же, как это делает клиент, и возвращает его вместе с параметрами заголовка.
+func (s signedRequest) build() (*http.Request, string) {
+	r := httptest.NewRequest(s.method, s.target, strings.NewReader(s.body))
+	timestamp := strconv.FormatInt(s.timestamp.Unix(), 10)
+	r.Header.Set(headerSignatureTimestamp, timestamp)
+	r.Header.Set(headerSignatureNonce, s.nonce)
+
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(stringToSign(r, timestamp, s.nonce, []byte(s.body))))
+	return r, "Credential=" + testClientID + ", Signature=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACAuthenticate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *signedRequest)
+		tamper  func(r *http.Request, params string) string
+		wantErr bool
+	}{
+		{name: "valid signature"},
+		{
+			name:   "timestamp within skew",
+			modify: func(s *signedRequest) { s.timestamp = time.Now().Add(-testMaxSkew + 10*time.Second) },
+		},
+		{
+			name: "tampered body",
+			tamper: func(r *http.Request, params string) string {
+				r.Body = io.NopCloser(strings.NewReader(`{"group":"Muse","song":"Uprising"}`))
+				return params
+			},
+			wantErr: true,
+		},
+		{
+			name: "tampered query",
+			tamper: func(r *http.Request, params string) string {
+				r.URL.RawQuery = "source=other"
+				return params
+			},
+			wantErr: true,
+		},
+		{
+			name: "tampered method",
+			tamper: func(r *http.Request, params string) string {
+				r.Method = http.MethodDelete
+				return params
+			},
+			wantErr: true,
+		},
+		{name: "wrong secret", modify: func(s *signedRequest) { s.secret = "other" }, wantErr: true},
+		{
+			name:    "stale timestamp",
+			modify:  func(s *signedRequest) { s.timestamp = time.Now().Add(-testMaxSkew - time.Minute) },
+			wantErr: true,
+		},
+		{
+			name:    "timestamp in the future",
+			modify:  func(s *signedRequest) { s.timestamp = time.Now().Add(testMaxSkew + time.Minute) },
+			wantErr: true,
+		},
+		{name: "empty nonce", modify: func(s *signedRequest) { s.nonce = "" }, wantErr: true},
+		{name: "nonce too long", modify: func(s *signedRequest) { s.nonce = strings.Repeat("n", maxNonceLength+1) }, wantErr: true},
+		{
+			name: "unknown credential",
+			tamper: func(r *http.Request, params string) string {
+				return strings.Replace(params, testClientID, "unknown", 1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "signature is not hex",
+			tamper:  func(r *http.Request, params string) string { return "Credential=" + testClientID + ", Signature=zz" },
+			wantErr: true,
+		},
+		{
+			name:    "malformed header",
+			tamper:  func(r *http.Request, params string) string { return "Credential" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHMACAuthenticator()
+
+			s := defaultSignedRequest()
+			if tt.modify != nil {
+				tt.modify(&s)
+			}
+			r, params := s.build()
+			if tt.tamper != nil {
+				params = tt.tamper(r, params)
+			}
+
+			principal, err := h.Authenticate(r, params)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidSignature) {
+					t.Fatalf("got error %v, want errInvalidSignature", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.Type != auth.PrincipalHMAC || principal.ID != testClientID {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+
+			// Следующие обработчики должны получить тело целиком.
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != s.body {
+				t.Errorf("body after authentication = %q, want %q", body, s.body)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticateRejectsReplayedNonce(t *testing.T) {
+	h := newTestHMACAuthenticator()
+	s := defaultSignedRequest()
+
+	r, params := s.build()
+	if _, err := h.Authenticate(r, params); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	r, params = s.build()
+	if _, err := h.Authenticate(r, params); !errors.Is(err, errInvalidSignature) {
+		t.Fatalf("replayed request: got %v, want errInvalidSignature", err)
+	}
+
+	s.nonce = "nonce-2"
+	r, params = s.build()
+	if _, err := h.Authenticate(r, params); err != nil {
+		t.Fatalf("request with a fresh nonce: %v", err)
+	}
+}
+
+func TestHMACAuthenticateDoesNotRememberNonceOfInvalidRequest(t *testing.T) {
+	h := newTestHMACAuthenticator()
+
+	forged := defaultSignedRequest()
+	forged.secret = "other"
+	r, params := forged.build()
+	if _, err := h.Authenticate(r, params); err == nil {
+		t.Fatal("forged request must be rejected")
+	}
+
+	r, params = defaultSignedRequest().build()
+	if _, err := h.Authenticate(r, params); err != nil {
+		t.Fatalf("valid request with the same nonce: %v", err)
+	}
+}
+
+func TestHMACAuthRejectsWhenNonceCacheIsFull(t *testing.T) {
+	h := NewHMACAuthenticator([]config.HMACClient{{ID: testClientID, Secret: testSecret}}, testMaxSkew, 1)
+	a := NewAuthenticator(nil, nil, h, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	handler := a.Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(nonce string) int {
+		s := defaultSignedRequest()
+		s.nonce = nonce
+		r, params := s.build()
+		r.Header.Set("Authorization", hmacScheme+" "+params)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		nonce string
+		want  int
+	}{
+		{nonce: "nonce-1", want: http.StatusOK},
+		{nonce: "nonce-2", want: http.StatusServiceUnavailable},
+		{nonce: "nonce-1", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := serve(tt.nonce); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.nonce, got, tt.want)
+		}
+	}
+}
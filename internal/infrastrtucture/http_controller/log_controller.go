@@ -0,0 +1,129 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/logging"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultLogLevelDuration = 15 * time.Minute
+	maxLogLevelDuration     = 24 * time.Hour
+)
+
+type LogController struct {
+	levels *logging.Levels
+	logger *slog.Logger
+}
+
+func NewLogController(levels *logging.Levels, logger *slog.Logger) *LogController {
+	return &LogController{
+		levels: levels,
+		logger: logger.With("controller", "LogController"),
+	}
+}
+
+// GetLogLevelsHandler
+// @Title Get log levels
+// @Description Show the effective log levels, including temporary ones
+// @Tag Admin
+// @Success  200  object  entities.LogLevelsResponse  "Log levels"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Route /api/v1/admin/log-level [get]
+func (c *LogController) GetLogLevelsHandler(w http.ResponseWriter, r *http.Request) {
+	c.writeLevels(w, r)
+}
+
+// SetLogLevelHandler
+// @Title Change log level temporarily
+// @Description Set the global or a package log level for a limited time; the configured level returns afterwards
+// @Tag Admin
+// @Param level body entities.SetLogLevelRequest true "Level, optional package and duration"
+// @Success  200  object  entities.LogLevelsResponse  "Log levels after the change"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Route /api/v1/admin/log-level [put]
+func (c *LogController) SetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		http.Error(w, "Invalid level: must be debug, info, warn or error", http.StatusBadRequest)
+		return
+	}
+
+	duration := defaultLogLevelDuration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 || duration > maxLogLevelDuration {
+			http.Error(w, "Invalid duration: must be positive and at most "+maxLogLevelDuration.String(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	override := c.levels.SetTemporary(req.Package, level, duration)
+	c.log(ctx).Warn("log level changed temporarily", "package", req.Package, "level", level, "expiresAt", override.ExpiresAt)
+
+	c.writeLevels(w, r)
+}
+
+// ResetLogLevelHandler
+// @Title Reset log level
+// @Description Drop a temporary log level before it expires
+// @Tag Admin
+// @Param  package  query  string  false  "Package name; empty resets the global level"  "persistence"
+// @Success  200  object  entities.LogLevelsResponse  "Log levels after the reset"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Route /api/v1/admin/log-level [delete]
+func (c *LogController) ResetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	pkg := r.URL.Query().Get("package")
+	c.levels.Reset(pkg)
+	c.log(r.Context()).Warn("temporary log level reset", "package", pkg)
+
+	c.writeLevels(w, r)
+}
+
+func (c *LogController) writeLevels(w http.ResponseWriter, r *http.Request) {
+	base, packages, overrides := c.levels.Snapshot()
+
+	response := entities.LogLevelsResponse{
+		Level:    base.String(),
+		Packages: make(map[string]string, len(packages)),
+	}
+	for pkg, level := range packages {
+		response.Packages[pkg] = level.String()
+	}
+	for _, o := range overrides {
+		response.Overrides = append(response.Overrides, entities.LogLevelOverride{
+			Package:   o.Package,
+			Level:     o.Level.String(),
+			ExpiresAt: o.ExpiresAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		c.log(r.Context()).Error("failed to encode response", "error", err)
+	}
+}
+
+func (c *LogController) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, c.logger)
+}
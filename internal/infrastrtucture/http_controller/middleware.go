@@ -1,22 +1,155 @@
 package http_controller
 
 import (
+	"context"
+	"crypto/subtle"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/service"
 	"effictiveMobile/pkg/config"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
 )
 
-func Auth(next http.Handler) http.Handler {
+const bearerPrefix = "Bearer "
+
+type Authenticator struct {
+	apiKeys  service.ApiKeyService
+	verifier auth.TokenVerifier
+	hmac     *HMACAuthenticator
+	config   *config.Store
+	logger   *slog.Logger
+}
+
+// NewAuthenticator создаёт middleware аутентификации.
+// verifier и hmac могут быть nil, тогда соответствующие схемы не принимаются.
+func NewAuthenticator(apiKeys service.ApiKeyService, verifier auth.TokenVerifier, hmac *HMACAuthenticator, cfg *config.Store, logger *slog.Logger) *Authenticator {
+	return &Authenticator{
+		apiKeys:  apiKeys,
+		verifier: verifier,
+		hmac:     hmac,
+		config:   cfg,
+		logger:   logger.With("middleware", "Auth"),
+	}
+}
+
+// Auth проверяет заголовок Authorization и кладёт клиента запроса в контекст.
+// Поддерживаются схемы "Bearer <jwt>", "HMAC-SHA256 <подпись>" и API-ключ без префикса.
+// Ключ credentials.api_key из конфига продолжает работать как служебный ключ со скоупом admin,
+// чтобы можно было выпустить первые ключи; он перечитывается при перезагрузке конфига.
+func (a *Authenticator) Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("Authorization") == "" {
+		key := r.Header.Get("Authorization")
+		if key == "" {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		if r.Header.Get("Authorization") != config.Config.ApiKey() {
+		if token, ok := strings.CutPrefix(key, bearerPrefix); ok {
+			a.authenticateBearer(w, r, next, token)
+			return
+		}
+
+		if params, ok := strings.CutPrefix(key, hmacScheme+" "); ok {
+			a.authenticateHMAC(w, r, next, params)
+			return
+		}
+
+		if bootstrap := a.config.Current().ApiKey(); bootstrap != "" && subtle.ConstantTimeCompare([]byte(key), []byte(bootstrap)) == 1 {
+			principal := &auth.Principal{
+				Type:   auth.PrincipalBootstrapKey,
+				ID:     "bootstrap",
+				Name:   "bootstrap",
+				Scopes: []string{auth.ScopeAdmin},
+			}
+			next.ServeHTTP(w, withPrincipal(r, principal))
+			return
+		}
+
+		principal, err := a.apiKeys.Authenticate(r.Context(), key)
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidApiKey) {
+				a.log(r.Context()).Error("api key authentication failed", "error", err)
+				http.Error(w, "Authentication failed", errorStatus(err))
+				return
+			}
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
+		next.ServeHTTP(w, withPrincipal(r, principal))
+	})
+}
+
+// withPrincipal кладёт клиента в контекст запроса и добавляет его в логгер запроса.
+func withPrincipal(r *http.Request, principal *auth.Principal) *http.Request {
+	requestctx.AddLogAttrs(r.Context(), "actorType", principal.Type, "actorID", principal.ID)
+	return r.WithContext(auth.WithPrincipal(r.Context(), principal))
+}
+
+func (a *Authenticator) authenticateBearer(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	if a.verifier == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	principal, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			a.log(r.Context()).Error("token verification failed", "error", err)
+			http.Error(w, "Authentication failed", errorStatus(err))
+			return
+		}
+		a.log(r.Context()).Warn("rejected bearer token", "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	next.ServeHTTP(w, withPrincipal(r, principal))
+}
+
+func (a *Authenticator) authenticateHMAC(w http.ResponseWriter, r *http.Request, next http.Handler, params string) {
+	if a.hmac == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	principal, err := a.hmac.Authenticate(r, params)
+	if err != nil {
+		if errors.Is(err, errNonceCacheFull) {
+			a.log(r.Context()).Warn("rejected signed request, nonce cache is full", "error", err)
+			http.Error(w, "Too many signed requests, retry later", http.StatusServiceUnavailable)
+			return
+		}
+		if !errors.Is(err, errInvalidSignature) {
+			a.log(r.Context()).Error("request signature verification failed", "error", err)
+			http.Error(w, "Authentication failed", errorStatus(err))
+			return
+		}
+		a.log(r.Context()).Warn("rejected signed request", "error", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	next.ServeHTTP(w, withPrincipal(r, principal))
+}
+
+// RequireScope пропускает запрос только если у клиента есть нужный скоуп.
+func RequireScope(scope string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.PrincipalFromContext(r.Context()).HasScope(scope) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (a *Authenticator) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, a.logger)
+}
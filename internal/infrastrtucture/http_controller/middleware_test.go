@@ -0,0 +1,112 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/pkg/config"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubApiKeyService принимает один ключ и возвращает для него principal.
+type stubApiKeyService struct {
+	service.ApiKeyService
+	key       string
+	principal *auth.Principal
+	err       error
+}
+
+func (s stubApiKeyService) Authenticate(_ context.Context, rawKey string) (*auth.Principal, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if rawKey != s.key {
+		return nil, service.ErrInvalidApiKey
+	}
+	return s.principal, nil
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "no principal", principal: nil, want: http.StatusForbidden},
+		{name: "missing scope", principal: &auth.Principal{Scopes: []string{auth.ScopeSongsRead}}, want: http.StatusForbidden},
+		{name: "matching scope", principal: &auth.Principal{Scopes: []string{auth.ScopeSongsWrite}}, want: http.StatusOK},
+		{name: "admin has every scope", principal: &auth.Principal{Scopes: []string{auth.ScopeAdmin}}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := RequireScope(auth.ScopeSongsWrite, func(http.ResponseWriter, *http.Request) { called = true })
+
+			r := httptest.NewRequest(http.MethodPost, "/api/songs", nil)
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestAuthWithApiKey(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Credentials.ApiKey = "bootstrap-key-0123456789"
+	keyOwner := &auth.Principal{Type: auth.PrincipalApiKey, ID: "7", Scopes: []string{auth.ScopeSongsRead}}
+
+	tests := []struct {
+		name      string
+		header    string
+		storeErr  error
+		want      int
+		wantScope string
+	}{
+		{name: "missing header", header: "", want: http.StatusUnauthorized},
+		{name: "unknown key", header: "sk_unknown", want: http.StatusUnauthorized},
+		{name: "stored key", header: "sk_valid", want: http.StatusOK, wantScope: auth.ScopeSongsRead},
+		{name: "bootstrap key is admin", header: "bootstrap-key-0123456789", want: http.StatusOK, wantScope: auth.ScopeAdmin},
+		{name: "key store unavailable", header: "sk_valid", storeErr: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := stubApiKeyService{key: "sk_valid", principal: keyOwner, err: tt.storeErr}
+			a := NewAuthenticator(keys, nil, nil, config.NewStore(&cfg), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			var got *auth.Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = auth.PrincipalFromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			a.Auth(next).ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.wantScope != "" && !got.HasScope(tt.wantScope) {
+				t.Errorf("principal %+v lacks scope %s", got, tt.wantScope)
+			}
+		})
+	}
+}
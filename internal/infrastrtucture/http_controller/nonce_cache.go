@@ -0,0 +1,70 @@
+package http_controller
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	errNonceUsed      = errors.New("nonce already used")
+	errNonceCacheFull = errors.New("nonce cache is full")
+)
+
+// nonceCache хранит недавно использованные nonce, чтобы отклонять повторы подписанных запросов.
+// Записи старше ttl не нужны, так как такие запросы и так отклоняются по времени подписи.
+// Живые записи никогда не вытесняются: иначе, прогнав достаточно подписанных запросов,
+// можно было бы вытеснить перехваченный nonce и повторить запрос. Когда кэш заполнен,
+// новые nonce отклоняются, пока старые не устареют.
+type nonceCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type nonceEntry struct {
+	key    string
+	seenAt time.Time
+}
+
+func newNonceCache(capacity int, ttl time.Duration) *nonceCache {
+	return &nonceCache{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+// Add запоминает nonce. Возвращает errNonceUsed, если он уже встречался в пределах ttl,
+// и errNonceCacheFull, если запомнить его негде.
+func (c *nonceCache) Add(key string, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictExpired(now)
+
+	if _, ok := c.entries[key]; ok {
+		return errNonceUsed
+	}
+	if c.order.Len() >= c.capacity {
+		return errNonceCacheFull
+	}
+
+	c.entries[key] = c.order.PushBack(&nonceEntry{key: key, seenAt: now})
+	return nil
+}
+
+func (c *nonceCache) evictExpired(now time.Time) {
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		entry := e.Value.(*nonceEntry)
+		if now.Sub(entry.seenAt) < c.ttl {
+			return
+		}
+		c.order.Remove(e)
+		delete(c.entries, entry.key)
+	}
+}
@@ -0,0 +1,63 @@
+package http_controller
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNonceCacheExpiresAfterTwiceMaxSkew(t *testing.T) {
+	h := NewHMACAuthenticator(nil, testMaxSkew, 10)
+	start := time.Now()
+
+	if err := h.nonces.Add("client:n", start); err != nil {
+		t.Fatal("first use must be accepted")
+	}
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  error
+	}{
+		{name: "replay right away", after: time.Second, want: errNonceUsed},
+		{name: "replay within maxSkew", after: testMaxSkew, want: errNonceUsed},
+		{name: "replay just before 2×maxSkew", after: 2*testMaxSkew - time.Second, want: errNonceUsed},
+		{name: "reuse after 2×maxSkew", after: 2 * testMaxSkew, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := h.nonces.Add("client:n", start.Add(tt.after)); !errors.Is(err, tt.want) {
+				t.Errorf("Add() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonceCacheRejectsNewNoncesWhenFull(t *testing.T) {
+	c := newNonceCache(3, time.Minute)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := c.Add("n"+strconv.Itoa(i), now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("n%d: %v", i, err)
+		}
+	}
+
+	// Живые nonce не вытесняются, иначе n0 можно было бы вытеснить и повторить.
+	if err := c.Add("n3", now.Add(10*time.Second)); !errors.Is(err, errNonceCacheFull) {
+		t.Fatalf("got %v, want errNonceCacheFull", err)
+	}
+	if err := c.Add("n0", now.Add(10*time.Second)); !errors.Is(err, errNonceUsed) {
+		t.Fatalf("replay of n0: got %v, want errNonceUsed", err)
+	}
+
+	// Когда n0 устаревает, место освобождается.
+	if err := c.Add("n3", now.Add(time.Minute)); err != nil {
+		t.Fatalf("after n0 expired: %v", err)
+	}
+	if c.order.Len() != 3 {
+		t.Errorf("cache holds %d entries, want 3", c.order.Len())
+	}
+}
@@ -0,0 +1,87 @@
+package http_controller
+
+import (
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/infrastrtucture/ratelimit"
+	"effictiveMobile/pkg/requestctx"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// QuotaFunc возвращает число запросов и окно для скоупа маршрута.
+type QuotaFunc func(scope string) (int, time.Duration)
+
+type RateLimiter struct {
+	store  ratelimit.Store
+	quota  QuotaFunc
+	logger *slog.Logger
+}
+
+func NewRateLimiter(store ratelimit.Store, quota QuotaFunc, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		quota:  quota,
+		logger: logger.With("middleware", "RateLimit"),
+	}
+}
+
+// Limit ограничивает частоту запросов клиента к маршруту со скоупом scope
+// по квоте rate_limit.scopes[scope] (или rate_limit.default).
+// Клиент определяется по аутентифицированному клиенту из контекста, а для анонимных запросов — по IP.
+// На nil-получателе ограничение отключено.
+func (l *RateLimiter) Limit(scope string, next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, window := l.quota(scope)
+
+		result, err := l.store.Take(r.Context(), rateLimitKey(r, scope), limit, window)
+		if err != nil {
+			// Недоступность хранилища счётчиков не должна останавливать API.
+			requestctx.Logger(r.Context(), l.logger).Error("rate limit store failed, allowing request", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resetSeconds := int(math.Ceil(time.Until(result.Reset).Seconds()))
+		if resetSeconds < 0 {
+			resetSeconds = 0
+		}
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitKey(r *http.Request, scope string) string {
+	if scope == "" {
+		scope = "anonymous"
+	}
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		return principal.Type + ":" + principal.ID + "|" + scope
+	}
+	return "ip:" + clientIP(r) + "|" + scope
+}
+
+// clientIP возвращает адрес, с которого пришёл запрос.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
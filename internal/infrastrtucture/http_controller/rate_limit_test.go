@@ -0,0 +1,120 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/infrastrtucture/ratelimit"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// recordingStore запоминает ключи и отвечает заранее заданным результатом.
+type recordingStore struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+}
+
+func (s *recordingStore) Take(_ context.Context, key string, limit int, _ time.Duration) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	result := s.result
+	result.Limit = limit
+	return result, s.err
+}
+
+func serveLimited(store ratelimit.Store, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	limiter := NewRateLimiter(store, func(string) (int, time.Duration) { return 5, time.Minute },
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	w := httptest.NewRecorder()
+	limiter.Limit("songs:read", next).ServeHTTP(w, r)
+	return w, called
+}
+
+func TestRateLimiterRejectsOverQuota(t *testing.T) {
+	store := &recordingStore{result: ratelimit.Result{Allowed: false, Remaining: 0, Reset: time.Now().Add(30 * time.Second)}}
+
+	w, called := serveLimited(store, httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil))
+
+	if called {
+		t.Error("handler must not run over quota")
+	}
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 29 || retryAfter > 30 {
+		t.Errorf("Retry-After = %q, want about 30 seconds", w.Header().Get("Retry-After"))
+	}
+	if got := w.Header().Get("RateLimit-Limit"); got != "5" {
+		t.Errorf("RateLimit-Limit = %q, want 5", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", got)
+	}
+}
+
+func TestRateLimiterAllowsWithinQuota(t *testing.T) {
+	store := &recordingStore{result: ratelimit.Result{Allowed: true, Remaining: 4, Reset: time.Now().Add(time.Minute)}}
+
+	w, called := serveLimited(store, httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil))
+
+	if !called || w.Code != http.StatusOK {
+		t.Fatalf("status = %d, handler called = %v", w.Code, called)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "4" {
+		t.Errorf("RateLimit-Remaining = %q, want 4", got)
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("Retry-After must only be set on 429")
+	}
+}
+
+func TestRateLimiterKey(t *testing.T) {
+	anonymous := httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil)
+	anonymous.RemoteAddr = "10.0.0.7:51234"
+
+	authenticated := httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil)
+	authenticated.RemoteAddr = "10.0.0.7:51234"
+	authenticated = authenticated.WithContext(auth.WithPrincipal(authenticated.Context(), &auth.Principal{Type: "api_key", ID: "42"}))
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{name: "anonymous by ip", req: anonymous, want: "ip:10.0.0.7|songs:read"},
+		{name: "authenticated by principal", req: authenticated, want: "api_key:42|songs:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{result: ratelimit.Result{Allowed: true}}
+			serveLimited(store, tt.req)
+			if len(store.keys) != 1 || store.keys[0] != tt.want {
+				t.Errorf("keys = %v, want [%s]", store.keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterFailsOpenOnStoreError(t *testing.T) {
+	store := &recordingStore{err: errors.New("connection refused")}
+
+	w, called := serveLimited(store, httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil))
+
+	if !called || w.Code != http.StatusOK {
+		t.Fatalf("status = %d, handler called = %v; store errors must not block requests", w.Code, called)
+	}
+	if w.Header().Get("RateLimit-Limit") != "" {
+		t.Error("quota headers must not be sent without a store result")
+	}
+}
@@ -0,0 +1,49 @@
+package http_controller
+
+import (
+	"crypto/rand"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	headerRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestContext кладёт в контекст ID запроса и адрес клиента.
+// ID берётся из заголовка X-Request-ID, если клиент его передал, иначе генерируется,
+// и в любом случае возвращается в ответе.
+func RequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(headerRequestID, requestID)
+
+		ctx := requestctx.WithRequestID(r.Context(), requestID)
+		ctx = requestctx.WithClientIP(ctx, clientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isValidRequestID принимает только короткие ID из печатных ASCII-символов, чтобы не пускать мусор в логи.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,57 @@
+package http_controller
+
+import (
+	"effictiveMobile/pkg/httpinfo"
+	"effictiveMobile/pkg/requestctx"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RequestLogger struct {
+	logger *slog.Logger
+}
+
+func NewRequestLogger(logger *slog.Logger) *RequestLogger {
+	return &RequestLogger{
+		logger: logger,
+	}
+}
+
+// Handle кладёт в контекст логгер запроса с его ID, маршрутом и методом, чтобы записи
+// контроллера, сервиса и репозитория по одному запросу можно было связать между собой.
+// Клиент добавляется в логгер после аутентификации. По завершении запроса пишется строка
+// access-лога с кодом ответа, размером тела и длительностью.
+// Должен стоять после RequestContext, который назначает ID запроса.
+func (l *RequestLogger) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+
+		attrs := []any{
+			"requestID", requestctx.RequestID(r.Context()),
+			"method", r.Method,
+			"route", httpinfo.Route(r),
+		}
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+			attrs = append(attrs, "traceID", spanContext.TraceID().String())
+		}
+		ctx := requestctx.WithLogger(r.Context(), l.logger, attrs...)
+
+		recorder := httpinfo.NewRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if recorder.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		requestctx.Logger(ctx, nil).Log(ctx, level, "request completed",
+			"path", r.URL.Path,
+			"status", recorder.Status,
+			"bytes", recorder.Bytes,
+			"durationMs", float64(time.Since(started).Microseconds())/1000,
+			"clientIP", requestctx.ClientIP(ctx),
+		)
+	})
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"effictiveMobile/internal/domain/entities"
 	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/internal/infrastrtucture/tracing"
+	"effictiveMobile/pkg/requestctx"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"log/slog"
 	"net/http"
 	"strconv"
 )
 
+// SongController — HTTP-обработчики песен. Каждый обработчик открывает спан SongController.<метод>
+// под серверным спаном Tracing: так в трассе видно время разбора запроса и записи ответа
+// отдельно от спанов сервиса и репозитория.
 type SongController struct {
 	songService service.SongService
 	logger      *slog.Logger
@@ -33,23 +40,27 @@ func NewSongController(songService service.SongService, logger *slog.Logger) *So
 // @Success  200  object  entities.SongsResponse   "Songs list with pagination"
 // @Failure  400  object  entities.ErrorResponse   "Invalid input parameters"
 // @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
 // @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
 // @Route /api/v1/songs [get]
 func (c *SongController) GetSongsHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.GetSongs")
+	defer span.End()
 
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		c.logger.Error("invalid limit parameter", "limit", limitStr, "error", err)
+		c.log(ctx).Error("invalid limit parameter", "limit", limitStr, "error", err)
 		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
 		return
 	}
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < 0 {
-		c.logger.Error("invalid offset parameter", "offset", offsetStr, "error", err)
+		c.log(ctx).Error("invalid offset parameter", "offset", offsetStr, "error", err)
 		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
 		return
 	}
@@ -61,15 +72,19 @@ func (c *SongController) GetSongsHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	songs, err := c.songService.GetSongs(ctx, filter, limit, offset)
-	if err != nil {
-		c.logger.Error("failed to retrieve songs", "error", err)
-		http.Error(w, "Failed to retrieve songs: "+err.Error(), http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to retrieve songs", "error", err)
+		http.Error(w, "Failed to retrieve songs: "+err.Error(), errorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(songs); err != nil {
-		c.logger.Error("failed to encode response", "error", err)
+		c.log(ctx).Error("failed to encode response", "error", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
@@ -82,37 +97,49 @@ func (c *SongController) GetSongsHandler(w http.ResponseWriter, r *http.Request)
 // @Success  200  object  entities.Song           "Detailed song information"
 // @Failure  400  object  entities.ErrorResponse  "Invalid song ID"
 // @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
 // @Failure  404  object  entities.ErrorResponse  "Song not found"
 // @Failure  500  object  entities.ErrorResponse  "Internal server error"
+// @Failure  504  object  entities.ErrorResponse  "Request timed out"
 // @Route /api/v1/songs/{id} [get]
 func (c *SongController) GetSongByIDHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.GetSongByID")
+	defer span.End()
 
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		c.logger.Error("invalid song ID", "id", idStr, "error", err)
+		c.log(ctx).Error("invalid song ID", "id", idStr, "error", err)
 		http.Error(w, "Invalid song ID", http.StatusBadRequest)
 		return
 	}
 
 	song, err := c.songService.GetSongByID(ctx, id)
-	if err != nil {
-		c.logger.Error("failed to retrieve song", "id", id, "error", err)
-		http.Error(w, "Failed to retrieve song: "+err.Error(), http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, persistence.ErrSongNotFound):
+		c.log(ctx).Warn("song not found", "id", id)
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to retrieve song", "id", id, "error", err)
+		http.Error(w, "Failed to retrieve song: "+err.Error(), errorStatus(err))
 		return
 	}
 
 	if song == nil {
-		c.logger.Warn("song not found", "id", id)
+		c.log(ctx).Warn("song not found", "id", id)
 		http.Error(w, "Song not found", http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(song); err != nil {
-		c.logger.Error("failed to encode response", "error", err)
+		c.log(ctx).Error("failed to encode response", "error", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
@@ -125,10 +152,15 @@ func (c *SongController) GetSongByIDHandler(w http.ResponseWriter, r *http.Reque
 // @Success 201 {object} entities.Song "Created song"
 // @Failure 400 {object} entities.ErrorResponse "Invalid input"
 // @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  409  object  entities.ErrorResponse   "Song already exists"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
 // @Failure 500 {object} entities.ErrorResponse "Internal server error"
+// @Failure 504 {object} entities.ErrorResponse "Request timed out"
 // @Route /api/songs [post]
 func (c *SongController) CreateSongHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.CreateSong")
+	defer span.End()
 
 	var req entities.CreateSongRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -139,7 +171,7 @@ func (c *SongController) CreateSongHandler(w http.ResponseWriter, r *http.Reques
 	// Получаем данные о песне через внешний API
 	details, err := c.songService.GetSongDetails(ctx, req.Group, req.Song)
 	if err != nil {
-		http.Error(w, "Failed to get song details: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to get song details: "+err.Error(), errorStatus(err))
 		return
 	}
 
@@ -150,12 +182,23 @@ func (c *SongController) CreateSongHandler(w http.ResponseWriter, r *http.Reques
 		ReleaseDate: details.ReleaseDate,
 		Text:        details.Text,
 		Link:        details.Link,
+		Visibility:  req.Visibility,
 	}
 
 	// Сохраняем песню в базе данных
 	err = c.songService.CreateSong(ctx, &song)
-	if err != nil {
-		http.Error(w, "Failed to create song: "+err.Error(), http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, service.ErrSongExists):
+		http.Error(w, "Song already exists", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrUnownedSongVisibility):
+		http.Error(w, "Song without an owner must be public", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to create song: "+err.Error(), errorStatus(err))
 		return
 	}
 
@@ -173,38 +216,55 @@ func (c *SongController) CreateSongHandler(w http.ResponseWriter, r *http.Reques
 // @Success  200  object  map[string]string  "Song updated successfully"
 // @Failure  400  object  entities.ErrorResponse   "Invalid input data"
 // @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope or not allowed to change visibility"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
 // @Failure  404  object  entities.ErrorResponse   "Song not found"
 // @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
 // @Route /api/v1/songs/update/{id} [put]
 func (c *SongController) UpdateSongHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.UpdateSong")
+	defer span.End()
 
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		c.logger.Error("invalid song ID", "id", idStr, "error", err)
+		c.log(ctx).Error("invalid song ID", "id", idStr, "error", err)
 		http.Error(w, "Invalid song ID", http.StatusBadRequest)
 		return
 	}
 
 	var song entities.Song
 	if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
-		c.logger.Error("invalid request body", "error", err)
+		c.log(ctx).Error("invalid request body", "error", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	err = c.songService.UpdateSong(ctx, id, &song)
-	if err != nil {
-		c.logger.Error("failed to update song", "songID", id, "song", song, "error", err)
-		http.Error(w, "Failed to update song: "+err.Error(), http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, persistence.ErrSongNotFound):
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrUnownedSongVisibility):
+		http.Error(w, "Song without an owner must be public", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrVisibilityForbidden):
+		http.Error(w, "Only the owner or an admin can change visibility", http.StatusForbidden)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to update song", "songID", id, "song", song, "error", err)
+		http.Error(w, "Failed to update song: "+err.Error(), errorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Song updated successfully"}); err != nil {
-		c.logger.Error("failed to encode response", "error", err)
+		c.log(ctx).Error("failed to encode response", "error", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
@@ -217,31 +277,221 @@ func (c *SongController) UpdateSongHandler(w http.ResponseWriter, r *http.Reques
 // @Success  200  object  map[string]string  "Song deleted successfully"
 // @Failure  400  object  entities.ErrorResponse   "Invalid song ID"
 // @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
 // @Failure  404  object  entities.ErrorResponse   "Song not found"
 // @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
 // @Route /api/v1/songs/delete/{id} [delete]
 func (c *SongController) DeleteSongHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.DeleteSong")
+	defer span.End()
 
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		c.logger.Error("invalid song ID", "id", idStr, "error", err)
+		c.log(ctx).Error("invalid song ID", "id", idStr, "error", err)
 		http.Error(w, "Invalid song ID", http.StatusBadRequest)
 		return
 	}
 
 	err = c.songService.DeleteSong(ctx, id)
-	if err != nil {
-		c.logger.Error("failed to delete song", "songID", id, "error", err)
-		http.Error(w, "Failed to delete song: "+err.Error(), http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, persistence.ErrSongNotFound):
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to delete song", "songID", id, "error", err)
+		http.Error(w, "Failed to delete song: "+err.Error(), errorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Song deleted successfully"}); err != nil {
-		c.logger.Error("failed to encode response", "error", err)
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ReEnrichSongHandler
+// @Title Re-enrich song details
+// @Description Fetch the song details from the external API again and overwrite every field that was not changed manually
+// @Tag Admin
+// @Param  id  path  int  true  "ID of the song"  "1"
+// @Success  200  object  entities.Song  "Song with refreshed details"
+// @Failure  400  object  entities.ErrorResponse   "Invalid song ID"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "Song not found"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/songs/re-enrich/{id} [post]
+func (c *SongController) ReEnrichSongHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.ReEnrichSong")
+	defer span.End()
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.log(ctx).Error("invalid song ID", "id", idStr, "error", err)
+		http.Error(w, "Invalid song ID", http.StatusBadRequest)
+		return
+	}
+
+	song, err := c.songService.ReEnrichSong(ctx, id)
+	switch {
+	case errors.Is(err, persistence.ErrSongNotFound):
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to re-enrich song", "songID", id, "error", err)
+		http.Error(w, "Failed to re-enrich song: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(song); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
+
+// GetChangeProposalsHandler
+// @Title Get proposed song changes
+// @Description Retrieve changes detected by the periodic re-enrichment of song details
+// @Tag Song
+// @Param  limit   query  int     true   "Number of proposals to return"          "10"
+// @Param  offset  query  int     true   "Offset for pagination"                  "0"
+// @Param  status  query  string  false  "Filter by status: pending, accepted, rejected"  "pending"
+// @Success  200  array   []entities.SongChangeProposal  "Proposed changes"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input parameters"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/songs/proposals [get]
+func (c *SongController) GetChangeProposalsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), tracerName, "SongController.GetChangeProposals")
+	defer span.End()
+
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		c.log(ctx).Error("invalid limit parameter", "limit", limitStr, "error", err)
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		c.log(ctx).Error("invalid offset parameter", "offset", offsetStr, "error", err)
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	proposals, err := c.songService.GetChangeProposals(ctx, r.URL.Query().Get("status"), limit, offset)
+	switch {
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to retrieve change proposals", "error", err)
+		http.Error(w, "Failed to retrieve change proposals: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(proposals); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// AcceptChangeProposalHandler
+// @Title Accept proposed song change
+// @Description Apply the value returned by the external API to the song
+// @Tag Song
+// @Param  id  path  int  true  "ID of the proposal"  "1"
+// @Success  200  object  map[string]string  "Proposal accepted"
+// @Failure  400  object  entities.ErrorResponse   "Invalid proposal ID"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "Proposal not found"
+// @Failure  409  object  entities.ErrorResponse   "Proposal already resolved"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/songs/proposals/accept/{id} [post]
+func (c *SongController) AcceptChangeProposalHandler(w http.ResponseWriter, r *http.Request) {
+	c.resolveChangeProposal(w, r, "SongController.AcceptChangeProposal", c.songService.AcceptChangeProposal, "Proposal accepted")
+}
+
+// RejectChangeProposalHandler
+// @Title Reject proposed song change
+// @Description Keep the stored value and discard the value returned by the external API
+// @Tag Song
+// @Param  id  path  int  true  "ID of the proposal"  "1"
+// @Success  200  object  map[string]string  "Proposal rejected"
+// @Failure  400  object  entities.ErrorResponse   "Invalid proposal ID"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "Proposal not found"
+// @Failure  409  object  entities.ErrorResponse   "Proposal already resolved"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/songs/proposals/reject/{id} [post]
+func (c *SongController) RejectChangeProposalHandler(w http.ResponseWriter, r *http.Request) {
+	c.resolveChangeProposal(w, r, "SongController.RejectChangeProposal", c.songService.RejectChangeProposal, "Proposal rejected")
+}
+
+func (c *SongController) resolveChangeProposal(w http.ResponseWriter, r *http.Request, spanName string, resolve func(ctx context.Context, id int) error, message string) {
+	ctx, span := tracing.Start(r.Context(), tracerName, spanName)
+	defer span.End()
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.log(ctx).Error("invalid proposal ID", "id", idStr, "error", err)
+		http.Error(w, "Invalid proposal ID", http.StatusBadRequest)
+		return
+	}
+
+	err = resolve(ctx, id)
+	switch {
+	case errors.Is(err, service.ErrProposalNotFound), errors.Is(err, persistence.ErrSongNotFound):
+		http.Error(w, "Proposal not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrProposalResolved):
+		http.Error(w, "Proposal already resolved", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrInvalidSongInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to resolve change proposal", "proposalID", id, "error", err)
+		http.Error(w, "Failed to resolve proposal: "+err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (c *SongController) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, c.logger)
+}
@@ -0,0 +1,116 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/internal/infrastrtucture/tracing"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubSongService возвращает err из методов, которые вызывают проверяемые обработчики.
+type stubSongService struct {
+	service.SongService
+	err error
+}
+
+func (s stubSongService) GetSongs(context.Context, map[string]interface{}, int, int) ([]entities.Song, error) {
+	return nil, s.err
+}
+
+func (s stubSongService) UpdateSong(context.Context, int, *entities.Song) error {
+	return s.err
+}
+
+func (s stubSongService) GetChangeProposals(context.Context, string, int, int) ([]entities.SongChangeProposal, error) {
+	return nil, s.err
+}
+
+func TestSongControllerErrorStatus(t *testing.T) {
+	invalid := fmt.Errorf("%w: invalid release date format", service.ErrInvalidSongInput)
+
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{name: "update with invalid release date", err: invalid, method: http.MethodPut, target: "/songs/update/1", body: `{"group":"Muse"}`, want: http.StatusBadRequest},
+		{name: "list with invalid filter", err: invalid, method: http.MethodGet, target: "/songs?limit=10&offset=0", want: http.StatusBadRequest},
+		{name: "proposals with unknown status", err: invalid, method: http.MethodGet, target: "/proposals?status=archived&limit=10&offset=0", want: http.StatusBadRequest},
+		{name: "update fails in the database", err: errors.New("connection reset"), method: http.MethodPut, target: "/songs/update/1", body: `{"group":"Muse"}`, want: http.StatusInternalServerError},
+		{name: "update times out", err: context.DeadlineExceeded, method: http.MethodPut, target: "/songs/update/1", body: `{"group":"Muse"}`, want: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSongController(stubSongService{err: tt.err}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			r := mux.NewRouter()
+			r.HandleFunc("/songs", c.GetSongsHandler)
+			r.HandleFunc("/songs/update/{id}", c.UpdateSongHandler)
+			r.HandleFunc("/proposals", c.GetChangeProposalsHandler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusBadRequest && !strings.Contains(w.Body.String(), "invalid release date format") {
+				t.Errorf("body %q must explain the validation error", w.Body.String())
+			}
+		})
+	}
+}
+
+// tracedSongService открывает спан, как это делает настоящий сервис.
+type tracedSongService struct {
+	stubSongService
+}
+
+func (s tracedSongService) GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]entities.Song, error) {
+	_, span := tracing.Start(ctx, "test", "SongService.GetSongs")
+	defer span.End()
+	return s.stubSongService.GetSongs(ctx, filter, limit, offset)
+}
+
+func TestSongControllerSpanNestsBetweenServerAndService(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := tracing.NewProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	c := NewSongController(tracedSongService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r := mux.NewRouter()
+	r.Use(Tracing)
+	r.HandleFunc("/songs", c.GetSongsHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/songs?limit=10&offset=0", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req.WithContext(provider.WithContext(req.Context())))
+
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans.Ended() {
+		byName[span.Name()] = span
+	}
+	server, controller, svc := byName["GET /songs"], byName["SongController.GetSongs"], byName["SongService.GetSongs"]
+	if server == nil || controller == nil || svc == nil {
+		t.Fatalf("missing spans, got %v", byName)
+	}
+	if controller.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("controller span must be a child of the server span")
+	}
+	if svc.Parent().SpanID() != controller.SpanContext().SpanID() {
+		t.Error("service span must be a child of the controller span")
+	}
+}
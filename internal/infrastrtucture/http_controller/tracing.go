@@ -0,0 +1,38 @@
+package http_controller
+
+import (
+	"effictiveMobile/internal/infrastrtucture/tracing"
+	"effictiveMobile/pkg/httpinfo"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const tracerName = "effictiveMobile/http"
+
+// Tracing открывает серверный спан на каждый запрос. Если клиент передал traceparent,
+// спан становится частью его трассы. Имя спана строится по шаблону маршрута, а не по пути,
+// чтобы запросы к разным песням группировались вместе.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+
+		route := httpinfo.Route(r)
+		ctx, span := tracing.StartServer(ctx, tracerName, r.Method+" "+route,
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(r.URL.Path),
+		)
+		defer span.End()
+
+		recorder := httpinfo.NewRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.Status))
+		if recorder.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", recorder.Status))
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package http_controller
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/domain/service"
+	"effictiveMobile/internal/infrastrtucture/persistence"
+	"effictiveMobile/pkg/requestctx"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type UserController struct {
+	userService service.UserService
+	logger      *slog.Logger
+}
+
+func NewUserController(userService service.UserService, logger *slog.Logger) *UserController {
+	return &UserController{
+		userService: userService,
+		logger:      logger.With("controller", "UserController"),
+	}
+}
+
+// RegisterHandler
+// @Title Register a user
+// @Description Create a user account with the viewer role
+// @Tag User
+// @Param user body entities.CredentialsRequest true "Username and password"
+// @Success  201  object  entities.User  "Registered user"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  409  object  entities.ErrorResponse   "Username is already taken"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/users/register [post]
+func (c *UserController) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := c.userService.Register(ctx, req.Username, req.Password)
+	switch {
+	case errors.Is(err, persistence.ErrUsernameTaken):
+		http.Error(w, "Username is already taken", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrInvalidUserInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to register user", "error", err)
+		http.Error(w, "Failed to register user", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+	}
+}
+
+// LoginHandler
+// @Title Log in
+// @Description Exchange username and password for a bearer token
+// @Tag User
+// @Param user body entities.CredentialsRequest true "Username and password"
+// @Success  200  object  entities.TokenResponse  "Access token"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  401  object  entities.ErrorResponse   "Invalid username or password"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/users/login [post]
+func (c *UserController) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, err := c.userService.Login(ctx, req.Username, req.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		c.log(ctx).Error("failed to log in", "error", err)
+		http.Error(w, "Failed to log in", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// SetUserRoleHandler
+// @Title Change user role
+// @Description Set the role of a user; applies to tokens issued afterwards
+// @Tag Admin
+// @Param  id    path  int                          true  "ID of the user"  "1"
+// @Param  role  body  entities.SetUserRoleRequest  true  "New role"
+// @Success  200  object  map[string]string  "Role updated"
+// @Failure  400  object  entities.ErrorResponse   "Invalid input"
+// @Failure  401  object  entities.ErrorResponse   "Unauthorized"
+// @Failure  403  object  entities.ErrorResponse   "Missing required scope"
+// @Failure  429  object  entities.ErrorResponse   "Too many requests"
+// @Failure  404  object  entities.ErrorResponse   "User not found"
+// @Failure  500  object  entities.ErrorResponse   "Internal server error"
+// @Failure  504  object  entities.ErrorResponse   "Request timed out"
+// @Route /api/v1/admin/users/role/{id} [put]
+func (c *UserController) SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		c.log(ctx).Error("invalid user ID", "id", idStr, "error", err)
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req entities.SetUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = c.userService.SetUserRole(ctx, id, req.Role)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidUserInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		c.log(ctx).Error("failed to set user role", "userID", id, "error", err)
+		http.Error(w, "Failed to set user role", errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Role updated"}); err != nil {
+		c.log(ctx).Error("failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (c *UserController) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, c.logger)
+}
@@ -0,0 +1,73 @@
+package jwt_verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseJWKSFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		t.Fatalf("parseJWKS: %v", err)
+	}
+
+	if len(keys) != 3 {
+		t.Errorf("got %d keys, want 3 (encryption and symmetric keys must be skipped)", len(keys))
+	}
+	if _, ok := keys["rsa-1"].(*rsa.PublicKey); !ok {
+		t.Errorf("rsa-1: got %T, want *rsa.PublicKey", keys["rsa-1"])
+	}
+	if _, ok := keys["ec-1"].(*ecdsa.PublicKey); !ok {
+		t.Errorf("ec-1: got %T, want *ecdsa.PublicKey", keys["ec-1"])
+	}
+	if _, ok := keys["ed-1"].(ed25519.PublicKey); !ok {
+		t.Errorf("ed-1: got %T, want ed25519.PublicKey", keys["ed-1"])
+	}
+	for _, kid := range []string{"rsa-enc", "hmac-1"} {
+		if _, ok := keys[kid]; ok {
+			t.Errorf("%s must be skipped", kid)
+		}
+	}
+}
+
+func TestParseJWKSErrors(t *testing.T) {
+	// Координаты точки P-256 валидной длины, но точка не лежит на кривой.
+	offCurve := strings.Repeat("A", 42) + "E"
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "invalid json", data: `{"keys": [`, want: "decode jwks"},
+		{name: "no keys", data: `{"keys": []}`, want: "no usable signing keys"},
+		{name: "only encryption keys", data: `{"keys": [{"kty": "RSA", "kid": "a", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`, want: "no usable signing keys"},
+		{name: "rsa exponent too small", data: `{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQ"}]}`, want: `jwk "a": unsupported exponent`},
+		{name: "rsa empty modulus", data: `{"keys": [{"kty": "RSA", "kid": "a", "n": "", "e": "AQAB"}]}`, want: `jwk "a": modulus`},
+		{name: "unsupported curve", data: `{"keys": [{"kty": "EC", "kid": "a", "crv": "P-192", "x": "AA", "y": "AA"}]}`, want: "unsupported curve"},
+		{name: "ec coordinate length", data: `{"keys": [{"kty": "EC", "kid": "a", "crv": "P-256", "x": "AA", "y": "AA"}]}`, want: "invalid coordinate length"},
+		{name: "ec point off curve", data: `{"keys": [{"kty": "EC", "kid": "a", "crv": "P-256", "x": "` + offCurve + `", "y": "` + offCurve + `"}]}`, want: `jwk "a"`},
+		{name: "ed25519 key length", data: `{"keys": [{"kty": "OKP", "kid": "a", "crv": "Ed25519", "x": "AAAA"}]}`, want: "invalid key length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJWKS([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
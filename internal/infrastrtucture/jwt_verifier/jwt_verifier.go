@@ -0,0 +1,230 @@
+package jwt_verifier
+
+import (
+	"context"
+	"crypto"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minReloadInterval ограничивает перечитывание JWKS, когда приходит токен с неизвестным kid.
+const minReloadInterval = time.Minute
+
+type Options struct {
+	// JWKSURL и JWKSFile — источник ключей; если задан URL, файл не используется.
+	JWKSURL         string
+	JWKSFile        string
+	Issuer          string
+	Audience        string
+	ScopeClaim      string
+	UserIDClaim     string
+	RefreshInterval time.Duration
+}
+
+// Verifier проверяет JWT, подписанные ключами из JWKS.
+type Verifier struct {
+	opts       Options
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	mu         sync.RWMutex
+	keys       map[string]crypto.PublicKey
+	lastReload time.Time
+}
+
+// NewVerifier загружает JWKS и возвращает готовый к работе верификатор.
+func NewVerifier(ctx context.Context, opts Options, logger *slog.Logger) (*Verifier, error) {
+	if opts.JWKSURL == "" && opts.JWKSFile == "" {
+		return nil, errors.New("jwks url or file is required")
+	}
+
+	v := &Verifier{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger.With("component", "JWTVerifier"),
+	}
+	if err := v.reload(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Verify проверяет подпись, срок действия, издателя и аудиторию токена
+// и переводит claims в клиента запроса. Неизвестные скоупы отбрасываются.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*auth.Principal, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if v.opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(v.opts.Issuer))
+	}
+	if v.opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(v.opts.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, v.keyFunc(ctx), parserOpts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+
+	userID, _ := claims[v.opts.UserIDClaim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", auth.ErrInvalidToken, v.opts.UserIDClaim)
+	}
+
+	name := userID
+	for _, claim := range []string{"preferred_username", "email", "name"} {
+		if s, ok := claims[claim].(string); ok && s != "" {
+			name = s
+			break
+		}
+	}
+
+	return &auth.Principal{
+		Type:   auth.PrincipalJWT,
+		ID:     userID,
+		Name:   name,
+		Scopes: scopesFromClaim(claims[v.opts.ScopeClaim]),
+		UserID: userID,
+	}, nil
+}
+
+func (v *Verifier) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		if v.expired() {
+			if err := v.reload(ctx); err != nil {
+				v.log(ctx).Warn("failed to refresh jwks, using cached keys", "error", err)
+			}
+		}
+
+		if key, ok := v.lookup(kid); ok {
+			return key, nil
+		}
+
+		// Ключ могли ротировать у провайдера: пробуем перечитать JWKS.
+		if v.reloadAllowed() {
+			if err := v.reload(ctx); err != nil {
+				v.log(ctx).Warn("failed to reload jwks", "error", err)
+			} else if key, ok := v.lookup(kid); ok {
+				return key, nil
+			}
+		}
+
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+}
+
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// expired сообщает, что JWKS, загруженный по URL, пора обновить.
+func (v *Verifier) expired() bool {
+	if v.opts.JWKSURL == "" || v.opts.RefreshInterval <= 0 {
+		return false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.lastReload) >= v.opts.RefreshInterval
+}
+
+func (v *Verifier) reloadAllowed() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.lastReload) >= minReloadInterval
+}
+
+func (v *Verifier) reload(ctx context.Context) error {
+	v.mu.Lock()
+	v.lastReload = time.Now()
+	v.mu.Unlock()
+
+	data, err := v.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	v.log(ctx).Info("jwks loaded", "keys", len(keys))
+	return nil
+}
+
+func (v *Verifier) fetch(ctx context.Context) ([]byte, error) {
+	if v.opts.JWKSURL == "" {
+		return os.ReadFile(v.opts.JWKSFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.opts.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// scopesFromClaim принимает скоупы строкой через пробел (OAuth2) или массивом строк.
+func scopesFromClaim(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	var scopes []string
+	for _, scope := range raw {
+		if auth.IsValidScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func (v *Verifier) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, v.logger)
+}
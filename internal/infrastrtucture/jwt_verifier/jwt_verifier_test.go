@@ -0,0 +1,169 @@
+package jwt_verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"effictiveMobile/internal/domain/auth"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://issuer.test"
+	testAudience = "songlib"
+	testKid      = "test-key"
+)
+
+// newTestVerifier записывает JWKS с публичной частью rsaKey во временный файл
+// и возвращает верификатор, читающий ключи из него.
+func newTestVerifier(t *testing.T, rsaKey *rsa.PublicKey) *Verifier {
+	t.Helper()
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": testKid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVerifier(context.Background(), Options{
+		JWKSFile:    path,
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		ScopeClaim:  "scope",
+		UserIDClaim: "sub",
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	return v
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                testIssuer,
+		"aud":                testAudience,
+		"sub":                "user-1",
+		"preferred_username": "alice",
+		"scope":              "openid songs:read songs:write",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return raw
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := newTestVerifier(t, &rsaKey.PublicKey)
+
+	with := func(key string, value any) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid token", token: sign(t, jwt.SigningMethodRS256, testKid, validClaims(), rsaKey)},
+		{name: "valid token without kid", token: sign(t, jwt.SigningMethodRS256, "", validClaims(), rsaKey)},
+		{name: "expired within leeway", token: sign(t, jwt.SigningMethodRS256, testKid, with("exp", time.Now().Add(-10*time.Second).Unix()), rsaKey)},
+		{name: "wrong issuer", token: sign(t, jwt.SigningMethodRS256, testKid, with("iss", "https://other.test"), rsaKey), wantErr: true},
+		{name: "wrong audience", token: sign(t, jwt.SigningMethodRS256, testKid, with("aud", "other"), rsaKey), wantErr: true},
+		{name: "expired", token: sign(t, jwt.SigningMethodRS256, testKid, with("exp", time.Now().Add(-time.Hour).Unix()), rsaKey), wantErr: true},
+		{name: "missing exp", token: sign(t, jwt.SigningMethodRS256, testKid, with("exp", nil), rsaKey), wantErr: true},
+		{name: "missing subject", token: sign(t, jwt.SigningMethodRS256, testKid, with("sub", nil), rsaKey), wantErr: true},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, "rotated-key", validClaims(), rsaKey), wantErr: true},
+		{name: "signed by another key", token: sign(t, jwt.SigningMethodRS256, testKid, validClaims(), otherRSAKey), wantErr: true},
+		{name: "alg mismatch: ES256 against RSA key", token: sign(t, jwt.SigningMethodES256, testKid, validClaims(), ecKey), wantErr: true},
+		{name: "alg mismatch: HS256 with public key as secret", token: sign(t, jwt.SigningMethodHS256, testKid, validClaims(), publicDER), wantErr: true},
+		{name: "alg none", token: sign(t, jwt.SigningMethodNone, testKid, validClaims(), jwt.UnsafeAllowNoneSignatureType), wantErr: true},
+		{name: "malformed", token: "not.a.token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := v.Verify(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrInvalidToken) {
+					t.Fatalf("got error %v, want auth.ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if principal.Type != auth.PrincipalJWT || principal.UserID != "user-1" || principal.Name != "alice" {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+			if want := []string{auth.ScopeSongsRead, auth.ScopeSongsWrite}; !slices.Equal(principal.Scopes, want) {
+				t.Errorf("scopes = %v, want %v (unknown scopes must be dropped)", principal.Scopes, want)
+			}
+		})
+	}
+}
+
+func TestNewVerifierRequiresSource(t *testing.T) {
+	_, err := NewVerifier(context.Background(), Options{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err == nil {
+		t.Fatal("expected an error without jwks url or file")
+	}
+}
@@ -0,0 +1,84 @@
+package jwt_verifier
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"effictiveMobile/internal/domain/entities"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type userClaims struct {
+	Role string `json:"role"`
+	Name string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// LocalIssuer выпускает и проверяет токены пользователей сервиса, подписанные HS256.
+type LocalIssuer struct {
+	key    []byte
+	issuer string
+	ttl    time.Duration
+}
+
+func NewLocalIssuer(key []byte, issuer string, ttl time.Duration) *LocalIssuer {
+	return &LocalIssuer{
+		key:    key,
+		issuer: issuer,
+		ttl:    ttl,
+	}
+}
+
+// IssueToken выпускает токен для пользователя и возвращает время его истечения.
+func (i *LocalIssuer) IssueToken(user *entities.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := userClaims{
+		Role: user.Role,
+		Name: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   strconv.Itoa(user.ID),
+			Audience:  jwt.ClaimStrings{i.issuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Verify проверяет токен, выпущенный IssueToken. Скоупы вычисляются по роли из токена.
+func (i *LocalIssuer) Verify(_ context.Context, raw string) (*auth.Principal, error) {
+	var claims userClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (interface{}, error) {
+		return i.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithAudience(i.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+	if !auth.IsValidRole(claims.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", auth.ErrInvalidToken, claims.Role)
+	}
+
+	return &auth.Principal{
+		Type:   auth.PrincipalUser,
+		ID:     claims.Subject,
+		Name:   claims.Name,
+		Scopes: auth.RoleScopes(claims.Role),
+		UserID: claims.Subject,
+	}, nil
+}
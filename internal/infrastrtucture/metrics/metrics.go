@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/httpinfo"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "song_library"
+
+// Metrics держит собственный реестр, чтобы несколько экземпляров сервиса в одном процессе не конфликтовали.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	externalRequests *prometheus.CounterVec
+	externalDuration *prometheus.HistogramVec
+
+	songsCreated prometheus.Counter
+	songsDeleted prometheus.Counter
+}
+
+func New(db *database.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		externalRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "external_api_requests_total",
+			Help:      "Calls to the song metadata provider by outcome.",
+		}, []string{"outcome"}),
+		externalDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "external_api_request_duration_seconds",
+			Help:      "Latency of calls to the song metadata provider by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		songsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "songs_created_total",
+			Help:      "Songs added to the library.",
+		}),
+		songsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "songs_deleted_total",
+			Help:      "Songs removed from the library.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.externalRequests, m.externalDuration,
+		m.songsCreated, m.songsDeleted,
+		newPoolCollector(db),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware считает запросы и их длительность. Маршрут берётся из шаблона gorilla/mux
+// (например /api/v1/songs/{id}), чтобы число рядов не зависело от ID в пути.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := httpinfo.NewRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		labels := prometheus.Labels{"route": httpinfo.Route(r), "method": r.Method, "status": strconv.Itoa(recorder.Status)}
+		m.httpRequests.With(labels).Inc()
+		m.httpDuration.With(labels).Observe(time.Since(started).Seconds())
+	})
+}
+
+// ObserveExternalCall учитывает один вызов внешнего API.
+func (m *Metrics) ObserveExternalCall(outcome string, duration time.Duration) {
+	m.externalRequests.WithLabelValues(outcome).Inc()
+	m.externalDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+func (m *Metrics) SongCreated() {
+	m.songsCreated.Inc()
+}
+
+func (m *Metrics) SongDeleted() {
+	m.songsDeleted.Inc()
+}
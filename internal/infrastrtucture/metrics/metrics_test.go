@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"effictiveMobile/pkg/database"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	m := New(&database.DB{})
+
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/songs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "404" {
+			http.Error(w, "Song not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+
+	for _, path := range []string{"/songs/1", "/songs/2", "/songs/404"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("/songs/{id}", http.MethodGet, "200")); got != 2 {
+		t.Errorf("200 responses = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("/songs/{id}", http.MethodGet, "404")); got != 1 {
+		t.Errorf("404 responses = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(m.httpRequests); got != 2 {
+		t.Errorf("series = %d, want 2: song IDs must not become labels", got)
+	}
+	if got := testutil.CollectAndCount(m.httpDuration); got != 2 {
+		t.Errorf("duration series = %d, want 2", got)
+	}
+}
+
+func TestHandlerExposesCounters(t *testing.T) {
+	m := New(&database.DB{})
+	m.SongCreated()
+	m.SongCreated()
+	m.SongDeleted()
+	m.ObserveExternalCall("success", 20*time.Millisecond)
+	m.ObserveExternalCall("circuit_open", 0)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"song_library_songs_created_total 2",
+		"song_library_songs_deleted_total 1",
+		`song_library_external_api_requests_total{outcome="success"} 1`,
+		`song_library_external_api_requests_total{outcome="circuit_open"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output lacks %q", want)
+		}
+	}
+	if strings.Contains(body, "song_library_db_pool_") {
+		t.Error("pool metrics must be skipped while there is no pool")
+	}
+}
+
+func TestInstancesUseSeparateRegistries(t *testing.T) {
+	first := New(&database.DB{})
+	second := New(&database.DB{})
+
+	first.SongCreated()
+
+	if got := testutil.ToFloat64(second.songsCreated); got != 0 {
+		t.Errorf("second instance counted %v songs created by the first", got)
+	}
+}
@@ -0,0 +1,61 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"errors"
+	"strconv"
+)
+
+var ErrSongNotFound = errors.New("song not found")
+
+// songReadFilter возвращает условие на песни, которые может видеть клиент из контекста:
+//   - без клиента (фоновые задачи) и с admin — все песни;
+//   - пользователь сервиса — публичные, общие и свои;
+//   - пользователь внешнего провайдера — публичные и общие;
+//   - интеграция по ключу — только публичные.
+//
+// column — префикс колонок таблицы songs в запросе, например "s.".
+// Аргументы условия нумеруются начиная с nextArg.
+func songReadFilter(ctx context.Context, column string, nextArg int) (string, []interface{}) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal.HasScope(auth.ScopeAdmin) {
+		return "TRUE", nil
+	}
+
+	if ownerID, ok := localUserID(principal); ok {
+		return "(" + column + "visibility IN ('public', 'shared') OR " + column + "owner_id = $" + strconv.Itoa(nextArg) + ")",
+			[]interface{}{ownerID}
+	}
+	if principal.UserID != "" {
+		return column + "visibility IN ('public', 'shared')", nil
+	}
+	return column + "visibility = 'public'", nil
+}
+
+// songWriteFilter возвращает условие на песни, которые клиент из контекста может изменять:
+// пользователь — свои и общие песни библиотеки без владельца, остальные клиенты — только песни без владельца.
+func songWriteFilter(ctx context.Context, column string, nextArg int) (string, []interface{}) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal.HasScope(auth.ScopeAdmin) {
+		return "TRUE", nil
+	}
+
+	if ownerID, ok := localUserID(principal); ok {
+		return "(" + column + "owner_id IS NULL OR " + column + "owner_id = $" + strconv.Itoa(nextArg) + ")",
+			[]interface{}{ownerID}
+	}
+	return column + "owner_id IS NULL", nil
+}
+
+// localUserID возвращает ID пользователя сервиса, если запрос выполняется от его имени.
+func localUserID(principal *auth.Principal) (int, bool) {
+	if principal.Type != auth.PrincipalUser {
+		return 0, false
+	}
+	id, err := strconv.Atoi(principal.UserID)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
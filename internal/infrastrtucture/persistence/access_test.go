@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/auth"
+	"reflect"
+	"testing"
+)
+
+var accessPrincipals = map[string]*auth.Principal{
+	"admin":        {Type: auth.PrincipalApiKey, Scopes: []string{auth.ScopeAdmin}},
+	"local user":   {Type: auth.PrincipalUser, UserID: "7", Scopes: []string{auth.ScopeSongsWrite}},
+	"bad local id": {Type: auth.PrincipalUser, UserID: "seven", Scopes: []string{auth.ScopeSongsWrite}},
+	"external jwt": {Type: auth.PrincipalJWT, UserID: "7", Scopes: []string{auth.ScopeSongsWrite}},
+	"api key":      {Type: auth.PrincipalApiKey, ID: "7", Scopes: []string{auth.ScopeSongsWrite}},
+}
+
+func accessContext(name string) context.Context {
+	if name == "no principal" {
+		return context.Background()
+	}
+	return auth.WithPrincipal(context.Background(), accessPrincipals[name])
+}
+
+func TestSongReadFilter(t *testing.T) {
+	tests := []struct {
+		principal string
+		wantSQL   string
+		wantArgs  []interface{}
+	}{
+		{principal: "no principal", wantSQL: "TRUE"},
+		{principal: "admin", wantSQL: "TRUE"},
+		{principal: "local user", wantSQL: "(s.visibility IN ('public', 'shared') OR s.owner_id = $3)", wantArgs: []interface{}{7}},
+		{principal: "bad local id", wantSQL: "s.visibility IN ('public', 'shared')"},
+		{principal: "external jwt", wantSQL: "s.visibility IN ('public', 'shared')"},
+		{principal: "api key", wantSQL: "s.visibility = 'public'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.principal, func(t *testing.T) {
+			sql, args := songReadFilter(accessContext(tt.principal), "s.", 3)
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSongWriteFilter(t *testing.T) {
+	tests := []struct {
+		principal string
+		wantSQL   string
+		wantArgs  []interface{}
+	}{
+		{principal: "no principal", wantSQL: "TRUE"},
+		{principal: "admin", wantSQL: "TRUE"},
+		{principal: "local user", wantSQL: "(owner_id IS NULL OR owner_id = $8)", wantArgs: []interface{}{7}},
+		{principal: "bad local id", wantSQL: "owner_id IS NULL"},
+		{principal: "external jwt", wantSQL: "owner_id IS NULL"},
+		{principal: "api key", wantSQL: "owner_id IS NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.principal, func(t *testing.T) {
+			sql, args := songWriteFilter(accessContext(tt.principal), "", 8)
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
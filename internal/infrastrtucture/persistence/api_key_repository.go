@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	GetApiKeyByHash(ctx context.Context, hash string) (*entities.ApiKey, error)
+	TouchApiKey(ctx context.Context, id int) error
+	CreateApiKey(ctx context.Context, key *entities.ApiKey) error
+	GetApiKeys(ctx context.Context, limit, offset int) ([]entities.ApiKey, error)
+	GetApiKeyByID(ctx context.Context, id int) (*entities.ApiKey, error)
+	SetApiKeyExpiry(ctx context.Context, id int, expiresAt time.Time) error
+	RevokeApiKey(ctx context.Context, id int) error
+}
+
+const apiKeyColumns = "id, name, key_prefix, key_hash, scopes, expires_at, last_used_at, created_at, revoked_at"
+
+type ApiKeyRepositoryImpl struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+func NewApiKeyRepository(db *database.DB, logger *slog.Logger) *ApiKeyRepositoryImpl {
+	return &ApiKeyRepositoryImpl{
+		db:     db,
+		logger: logger.With(slog.String("repository", "ApiKeyRepository")),
+	}
+}
+
+// GetApiKeyByHash ищет ключ по хэшу, в том числе отозванный или просроченный.
+func (r *ApiKeyRepositoryImpl) GetApiKeyByHash(ctx context.Context, hash string) (*entities.ApiKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = $1"
+
+	key, err := scanApiKey(r.db.Conn(ctx).QueryRow(ctx, query, hash))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying api key by hash", "error", err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// TouchApiKey обновляет время последнего использования ключа.
+// Чтобы не писать в базу на каждый запрос, время обновляется не чаще раза в минуту.
+func (r *ApiKeyRepositoryImpl) TouchApiKey(ctx context.Context, id int) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	_, err := r.db.Conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		r.log(ctx).Error("error updating api key last use", "error", err, "id", id)
+	}
+	return err
+}
+
+// CreateApiKey сохраняет новый ключ; в базу попадает только хэш.
+func (r *ApiKeyRepositoryImpl) CreateApiKey(ctx context.Context, key *entities.ApiKey) error {
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.Conn(ctx).QueryRow(ctx, query, key.Name, key.Prefix, key.Hash, key.Scopes, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		r.log(ctx).Error("error creating api key", "error", err, "name", key.Name)
+	}
+	return err
+}
+
+// GetApiKeys возвращает ключи с метаданными, новые первыми.
+func (r *ApiKeyRepositoryImpl) GetApiKeys(ctx context.Context, limit, offset int) ([]entities.ApiKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id DESC LIMIT $1 OFFSET $2"
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit, offset)
+	if err != nil {
+		r.log(ctx).Error("error querying api keys", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []entities.ApiKey
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			r.log(ctx).Error("error scanning api key row", "error", err)
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetApiKeyByID возвращает ключ по ID.
+func (r *ApiKeyRepositoryImpl) GetApiKeyByID(ctx context.Context, id int) (*entities.ApiKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = $1"
+
+	key, err := scanApiKey(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying api key by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// SetApiKeyExpiry меняет время истечения ключа.
+func (r *ApiKeyRepositoryImpl) SetApiKeyExpiry(ctx context.Context, id int, expiresAt time.Time) error {
+	query := "UPDATE api_keys SET expires_at = $1 WHERE id = $2"
+
+	_, err := r.db.Conn(ctx).Exec(ctx, query, expiresAt, id)
+	if err != nil {
+		r.log(ctx).Error("error updating api key expiry", "error", err, "id", id)
+	}
+	return err
+}
+
+// RevokeApiKey немедленно отзывает ключ.
+func (r *ApiKeyRepositoryImpl) RevokeApiKey(ctx context.Context, id int) error {
+	query := "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+
+	_, err := r.db.Conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		r.log(ctx).Error("error revoking api key", "error", err, "id", id)
+	}
+	return err
+}
+
+func scanApiKey(row pgx.Row) (*entities.ApiKey, error) {
+	var k entities.ApiKey
+	if err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, &k.Scopes, &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt, &k.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *ApiKeyRepositoryImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, r.logger)
+}
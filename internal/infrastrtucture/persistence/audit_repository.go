@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"log/slog"
+	"strconv"
+)
+
+type AuditRepository interface {
+	CreateAuditEntry(ctx context.Context, entry *entities.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]entities.AuditEntry, error)
+}
+
+const auditColumns = `id, actor_type, actor_id, actor_name, action, entity_type, entity_id,
+	COALESCE(before_hash, ''), COALESCE(after_hash, ''), request_id, client_ip, details, created_at`
+
+type AuditRepositoryImpl struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+func NewAuditRepository(db *database.DB, logger *slog.Logger) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{
+		db:     db,
+		logger: logger.With(slog.String("repository", "AuditRepository")),
+	}
+}
+
+// CreateAuditEntry добавляет запись в журнал аудита.
+func (r *AuditRepositoryImpl) CreateAuditEntry(ctx context.Context, entry *entities.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_type, actor_id, actor_name, action, entity_type, entity_id,
+		                       before_hash, after_hash, request_id, client_ip, details)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11)
+		RETURNING id, created_at
+	`
+
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
+		entry.ActorType, entry.ActorID, entry.ActorName, entry.Action, entry.EntityType, entry.EntityID,
+		entry.BeforeHash, entry.AfterHash, entry.RequestID, entry.ClientIP, details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		r.log(ctx).Error("error creating audit entry", "error", err, "action", entry.Action)
+	}
+	return err
+}
+
+// GetAuditEntries возвращает записи журнала по фильтру, новые первыми.
+func (r *AuditRepositoryImpl) GetAuditEntries(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]entities.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE 1=1"
+	args := []interface{}{}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += " AND " + condition + " $" + strconv.Itoa(len(args))
+	}
+	if filter.ActorType != "" {
+		addCondition("actor_type =", filter.ActorType)
+	}
+	if filter.ActorID != "" {
+		addCondition("actor_id =", filter.ActorID)
+	}
+	if filter.Action != "" {
+		addCondition("action =", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type =", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id =", filter.EntityID)
+	}
+	if filter.From != nil {
+		addCondition("created_at >=", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at <", *filter.To)
+	}
+
+	args = append(args, limit, offset)
+	query += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("error querying audit entries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entities.AuditEntry
+	for rows.Next() {
+		var e entities.AuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorType, &e.ActorID, &e.ActorName, &e.Action, &e.EntityType, &e.EntityID,
+			&e.BeforeHash, &e.AfterHash, &e.RequestID, &e.ClientIP, &e.Details, &e.CreatedAt); err != nil {
+			r.log(ctx).Error("error scanning audit entry row", "error", err)
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *AuditRepositoryImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, r.logger)
+}
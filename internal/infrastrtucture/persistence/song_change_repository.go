@@ -0,0 +1,152 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+)
+
+// ErrProposalNotPending возвращается, если предложение уже было принято или отклонено.
+var ErrProposalNotPending = errors.New("change proposal is not pending")
+
+type SongChangeRepository interface {
+	SaveProposal(ctx context.Context, proposal *entities.SongChangeProposal) error
+	GetProposals(ctx context.Context, status string, limit, offset int) ([]entities.SongChangeProposal, error)
+	GetProposalByID(ctx context.Context, id int) (*entities.SongChangeProposal, error)
+	ResolveProposal(ctx context.Context, id int, status string) error
+}
+
+const proposalColumns = "id, song_id, field, current_value, proposed_value, status, created_at, resolved_at"
+
+const joinedProposalColumns = "p.id, p.song_id, p.field, p.current_value, p.proposed_value, p.status, p.created_at, p.resolved_at"
+
+type SongChangeRepositoryImpl struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+func NewSongChangeRepository(db *database.DB, logger *slog.Logger) *SongChangeRepositoryImpl {
+	return &SongChangeRepositoryImpl{
+		db:     db,
+		logger: logger.With(slog.String("repository", "SongChangeRepository")),
+	}
+}
+
+// SaveProposal сохраняет предложенное изменение поля песни.
+// Для пары (песня, поле) хранится не больше одного ожидающего решения предложения:
+// новое значение из внешнего API заменяет предыдущее.
+func (r *SongChangeRepositoryImpl) SaveProposal(ctx context.Context, proposal *entities.SongChangeProposal) error {
+	query := `
+		INSERT INTO song_change_proposals (song_id, field, current_value, proposed_value, status)
+		VALUES ($1, $2, $3, $4, 'pending')
+		ON CONFLICT (song_id, field) WHERE status = 'pending'
+		DO UPDATE SET current_value = EXCLUDED.current_value,
+		              proposed_value = EXCLUDED.proposed_value,
+		              created_at = CASE
+		                  WHEN song_change_proposals.proposed_value = EXCLUDED.proposed_value
+		                  THEN song_change_proposals.created_at
+		                  ELSE NOW()
+		              END
+		RETURNING ` + proposalColumns
+
+	row := r.db.Conn(ctx).QueryRow(ctx, query, proposal.SongID, proposal.Field, proposal.CurrentValue, proposal.ProposedValue)
+	saved, err := scanProposal(row)
+	if err != nil {
+		r.log(ctx).Error("error saving change proposal", "error", err, "songID", proposal.SongID, "field", proposal.Field)
+		return err
+	}
+
+	*proposal = *saved
+	return nil
+}
+
+// GetProposals возвращает предложенные изменения, при непустом status — только с этим статусом.
+// В выборку попадают только изменения песен, которые может видеть клиент из контекста.
+func (r *SongChangeRepositoryImpl) GetProposals(ctx context.Context, status string, limit, offset int) ([]entities.SongChangeProposal, error) {
+	access, accessArgs := songReadFilter(ctx, "s.", 4)
+	query := `
+		SELECT ` + joinedProposalColumns + `
+		FROM song_change_proposals p
+		JOIN songs s ON s.id = p.song_id
+		WHERE ($1 = '' OR p.status = $1) AND ` + access + `
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, append([]interface{}{status, limit, offset}, accessArgs...)...)
+	if err != nil {
+		r.log(ctx).Error("error querying change proposals", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proposals []entities.SongChangeProposal
+	for rows.Next() {
+		proposal, err := scanProposal(rows)
+		if err != nil {
+			r.log(ctx).Error("error scanning change proposal row", "error", err)
+			return nil, err
+		}
+		proposals = append(proposals, *proposal)
+	}
+
+	return proposals, rows.Err()
+}
+
+// GetProposalByID возвращает предложенное изменение по ID, если клиент из контекста может видеть песню.
+func (r *SongChangeRepositoryImpl) GetProposalByID(ctx context.Context, id int) (*entities.SongChangeProposal, error) {
+	access, accessArgs := songReadFilter(ctx, "s.", 2)
+	query := `
+		SELECT ` + joinedProposalColumns + `
+		FROM song_change_proposals p
+		JOIN songs s ON s.id = p.song_id
+		WHERE p.id = $1 AND ` + access
+
+	proposal, err := scanProposal(r.db.Conn(ctx).QueryRow(ctx, query, append([]interface{}{id}, accessArgs...)...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying change proposal by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// ResolveProposal переводит ожидающее предложение в итоговый статус.
+// Если предложение успели разрешить параллельно, возвращает ErrProposalNotPending,
+// и транзакция, в которой его применяли, откатывается.
+func (r *SongChangeRepositoryImpl) ResolveProposal(ctx context.Context, id int, status string) error {
+	query := `
+		UPDATE song_change_proposals
+		SET status = $1, resolved_at = NOW()
+		WHERE id = $2 AND status = 'pending'
+	`
+
+	tag, err := r.db.Conn(ctx).Exec(ctx, query, status, id)
+	if err != nil {
+		r.log(ctx).Error("error resolving change proposal", "error", err, "id", id, "status", status)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProposalNotPending
+	}
+	return nil
+}
+
+func scanProposal(row pgx.Row) (*entities.SongChangeProposal, error) {
+	var p entities.SongChangeProposal
+	if err := row.Scan(&p.ID, &p.SongID, &p.Field, &p.CurrentValue, &p.ProposedValue, &p.Status, &p.CreatedAt, &p.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *SongChangeRepositoryImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, r.logger)
+}
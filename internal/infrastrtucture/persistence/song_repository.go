@@ -3,17 +3,42 @@ package persistence
 import (
 	"context"
 	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/internal/infrastrtucture/tracing"
 	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"fmt"
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
 	"log/slog"
+	"strconv"
+	"time"
 )
 
+// tracerName — имя, под которым репозиторий пишет спаны запросов к базе.
+const tracerName = "effictiveMobile/persistence"
+
 type SongRepository interface {
 	GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]entities.Song, error)
 	GetSongByID(ctx context.Context, id int) (*entities.Song, error)
 	CreateSong(ctx context.Context, song *entities.Song) error
 	UpdateSong(ctx context.Context, id int, song *entities.Song) error
 	DeleteSong(ctx context.Context, id int) error
+	GetStaleSongs(ctx context.Context, enrichedBefore time.Time, limit int) ([]entities.Song, error)
+	MarkSongEnriched(ctx context.Context, id int, enrichedAt time.Time) error
+	MarkSongEnrichFailed(ctx context.Context, id int, failedAt time.Time) error
+	UpdateSongField(ctx context.Context, id int, field, value string) error
+	GetSongByGroupAndTitle(ctx context.Context, group, title string, ownerID *int) (*entities.Song, error)
+	GetFieldProvenance(ctx context.Context, songID int) (map[string]entities.FieldProvenance, error)
+	SaveFieldProvenance(ctx context.Context, songID int, field string, provenance entities.FieldProvenance) error
+}
+
+const songColumns = `id, "group", song, release_date, text, link, enriched_at, owner_id, visibility`
+
+// enrichableColumns перечисляет поля, которые можно менять точечно через UpdateSongField.
+var enrichableColumns = map[string]bool{
+	entities.SongFieldReleaseDate: true,
+	entities.SongFieldText:        true,
+	entities.SongFieldLink:        true,
 }
 
 type SongRepositoryImpl struct {
@@ -29,89 +54,299 @@ func NewSongRepository(db *database.DB, logger *slog.Logger) *SongRepositoryImpl
 }
 
 // GetSongs возвращает список песен с возможностью фильтрации и пагинации.
-func (r *SongRepositoryImpl) GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]entities.Song, error) {
-	var songs []entities.Song
+// В выборку попадают только песни, которые может видеть клиент из контекста.
+func (r *SongRepositoryImpl) GetSongs(ctx context.Context, filter map[string]interface{}, limit, offset int) (_ []entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.GetSongs")
+	defer tracing.End(span, &err)
 
-	query := "SELECT id, \"group\", song, release_date, text, link FROM songs WHERE 1=1"
-	args := []interface{}{}
-	argIndex := 1
+	access, args := songReadFilter(ctx, "", 1)
+	query := "SELECT " + songColumns + " FROM songs WHERE " + access
+	argIndex := len(args) + 1
 
 	for field, value := range filter {
-		query += " AND " + field + " = $" + string(argIndex)
+		query += " AND " + pgx.Identifier{field}.Sanitize() + " = $" + strconv.Itoa(argIndex)
 		args = append(args, value)
 		argIndex++
 	}
 
-	query += " LIMIT $" + string(argIndex) + " OFFSET $" + string(argIndex+1)
+	query += " ORDER BY id LIMIT $" + strconv.Itoa(argIndex) + " OFFSET $" + strconv.Itoa(argIndex+1)
 	args = append(args, limit, offset)
 
-	rows, err := r.db.Conn.Query(ctx, query, args...)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, args...)
 	if err != nil {
-		r.logger.Error("error querying songs", "error", err, "query", query)
+		r.log(ctx).Error("error querying songs", "error", err, "query", query)
 		return nil, err
 	}
 	defer rows.Close()
 
-	for rows.Next() {
-		var song entities.Song
-		if err := rows.Scan(&song.ID, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link); err != nil {
-			r.logger.Error("error scanning song row", "error", err)
-			return nil, err
-		}
-		songs = append(songs, song)
+	songs, err := scanSongs(rows)
+	if err != nil {
+		r.log(ctx).Error("error scanning song row", "error", err)
+		return nil, err
 	}
 
 	return songs, nil
 }
 
-// GetSongByID возвращает одну песню по ID.
-func (r *SongRepositoryImpl) GetSongByID(ctx context.Context, id int) (*entities.Song, error) {
-	query := "SELECT id, \"group\", song, release_date, text, link FROM songs WHERE id = $1"
-	row := r.db.Conn.QueryRow(ctx, query, id)
+// GetSongByID возвращает одну песню по ID, если клиент из контекста может её видеть.
+func (r *SongRepositoryImpl) GetSongByID(ctx context.Context, id int) (_ *entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.GetSongByID", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
 
-	var song entities.Song
-	if err := row.Scan(&song.ID, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link); err != nil {
+	access, args := songReadFilter(ctx, "", 2)
+	query := "SELECT " + songColumns + " FROM songs WHERE id = $1 AND " + access
+	row := r.db.Conn(ctx).QueryRow(ctx, query, append([]interface{}{id}, args...)...)
+
+	song, err := scanSong(row)
+	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
-		r.logger.Error("error querying song by ID", "error", err, "id", id)
+		r.log(ctx).Error("error querying song by ID", "error", err, "id", id)
 		return nil, err
 	}
 
-	return &song, nil
+	return song, nil
 }
 
 // CreateSong добавляет новую песню в базу данных.
-func (r *SongRepositoryImpl) CreateSong(ctx context.Context, song *entities.Song) error {
-	query := `INSERT INTO songs ("group", song, release_date, text, link) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Conn.Exec(ctx, query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link)
+// Детали песни к этому моменту уже получены из внешнего API, поэтому enriched_at выставляется сразу.
+func (r *SongRepositoryImpl) CreateSong(ctx context.Context, song *entities.Song) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.CreateSong")
+	defer tracing.End(span, &err)
+
+	query := `
+		INSERT INTO songs ("group", song, release_date, text, link, enriched_at, owner_id, visibility)
+		VALUES ($1, $2, $3, $4, $5, NOW(), $6, $7)
+		RETURNING id, enriched_at
+	`
+	err = r.db.Conn(ctx).QueryRow(ctx, query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, song.OwnerID, song.Visibility).
+		Scan(&song.ID, &song.EnrichedAt)
 	if err != nil {
-		r.logger.Error("error creating song", "error", err, "song", song)
+		r.log(ctx).Error("error creating song", "error", err, "song", song)
 	}
 	return err
 }
 
 // UpdateSong обновляет данные о песне по ID.
-func (r *SongRepositoryImpl) UpdateSong(ctx context.Context, id int, song *entities.Song) error {
+// Если песни нет или клиент из контекста не может её изменять, возвращается ErrSongNotFound.
+func (r *SongRepositoryImpl) UpdateSong(ctx context.Context, id int, song *entities.Song) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.UpdateSong", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	access, args := songWriteFilter(ctx, "", 8)
 	query := `
 		UPDATE songs
-		SET "group" = $1, song = $2, release_date = $3, text = $4, link = $5
-		WHERE id = $6
+		SET "group" = $1, song = $2, release_date = $3, text = $4, link = $5, visibility = $6
+		WHERE id = $7 AND ` + access
+
+	args = append([]interface{}{song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, song.Visibility, id}, args...)
+	tag, err := r.db.Conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("error updating song", "error", err, "songID", id, "song", song)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSongNotFound
+	}
+	return nil
+}
+
+// DeleteSong удаляет песню по ID.
+// Если песни нет или клиент из контекста не может её изменять, возвращается ErrSongNotFound.
+func (r *SongRepositoryImpl) DeleteSong(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.DeleteSong", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	access, args := songWriteFilter(ctx, "", 2)
+	query := "DELETE FROM songs WHERE id = $1 AND " + access
+	tag, err := r.db.Conn(ctx).Exec(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		r.log(ctx).Error("error deleting song", "error", err, "songID", id)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSongNotFound
+	}
+	return nil
+}
+
+// GetStaleSongs возвращает песни, детали которых не обновлялись с enrichedBefore.
+// Песни, которые ещё ни разу не обогащались, идут первыми. Песни, запрос деталей которых
+// не удался после enrichedBefore, пропускаются до следующего окна.
+func (r *SongRepositoryImpl) GetStaleSongs(ctx context.Context, enrichedBefore time.Time, limit int) (_ []entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.GetStaleSongs")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT ` + songColumns + `
+		FROM songs
+		WHERE (enriched_at IS NULL OR enriched_at < $1)
+		  AND (enrich_failed_at IS NULL OR enrich_failed_at < $1)
+		ORDER BY enriched_at NULLS FIRST, id
+		LIMIT $2
 	`
 
-	_, err := r.db.Conn.Exec(ctx, query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, id)
+	rows, err := r.db.Conn(ctx).Query(ctx, query, enrichedBefore, limit)
+	if err != nil {
+		r.log(ctx).Error("error querying stale songs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs, err := scanSongs(rows)
 	if err != nil {
-		r.logger.Error("error updating song", "error", err, "songID", id, "song", song)
+		r.log(ctx).Error("error scanning song row", "error", err)
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// MarkSongEnriched фиксирует время последнего обращения к внешнему API за деталями песни.
+func (r *SongRepositoryImpl) MarkSongEnriched(ctx context.Context, id int, enrichedAt time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.MarkSongEnriched", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	query := "UPDATE songs SET enriched_at = $1, enrich_failed_at = NULL WHERE id = $2"
+	_, err = r.db.Conn(ctx).Exec(ctx, query, enrichedAt, id)
+	if err != nil {
+		r.log(ctx).Error("error marking song enriched", "error", err, "songID", id)
 	}
 	return err
 }
 
-// DeleteSong удаляет песню по ID.
-func (r *SongRepositoryImpl) DeleteSong(ctx context.Context, id int) error {
-	query := "DELETE FROM songs WHERE id = $1"
-	_, err := r.db.Conn.Exec(ctx, query, id)
+// MarkSongEnrichFailed фиксирует неудачный запрос деталей песни, откладывая следующую попытку.
+func (r *SongRepositoryImpl) MarkSongEnrichFailed(ctx context.Context, id int, failedAt time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.MarkSongEnrichFailed", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	query := "UPDATE songs SET enrich_failed_at = $1 WHERE id = $2"
+	_, err = r.db.Conn(ctx).Exec(ctx, query, failedAt, id)
 	if err != nil {
-		r.logger.Error("error deleting song", "error", err, "songID", id)
+		r.log(ctx).Error("error marking song enrichment failed", "error", err, "songID", id)
 	}
 	return err
 }
+
+// UpdateSongField обновляет одно из обогащаемых полей песни.
+// Если песни нет или клиент из контекста не может её изменять, возвращается ErrSongNotFound.
+func (r *SongRepositoryImpl) UpdateSongField(ctx context.Context, id int, field, value string) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.UpdateSongField", attribute.Int("song.id", id))
+	defer tracing.End(span, &err)
+
+	if !enrichableColumns[field] {
+		return fmt.Errorf("field %q cannot be updated", field)
+	}
+
+	access, args := songWriteFilter(ctx, "", 3)
+	query := "UPDATE songs SET " + pgx.Identifier{field}.Sanitize() + " = $1 WHERE id = $2 AND " + access
+	tag, err := r.db.Conn(ctx).Exec(ctx, query, append([]interface{}{value, id}, args...)...)
+	if err != nil {
+		r.log(ctx).Error("error updating song field", "error", err, "songID", id, "field", field)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSongNotFound
+	}
+	return nil
+}
+
+// GetSongByGroupAndTitle ищет песню с такими группой и названием у владельца ownerID,
+// а при nil — среди песен библиотеки без владельца. Права клиента из контекста не учитываются:
+// метод нужен, чтобы не создавать дубликаты.
+func (r *SongRepositoryImpl) GetSongByGroupAndTitle(ctx context.Context, group, title string, ownerID *int) (_ *entities.Song, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.GetSongByGroupAndTitle")
+	defer tracing.End(span, &err)
+
+	query := "SELECT " + songColumns + ` FROM songs WHERE "group" = $1 AND song = $2 AND owner_id IS NOT DISTINCT FROM $3 ORDER BY id LIMIT 1`
+
+	song, err := scanSong(r.db.Conn(ctx).QueryRow(ctx, query, group, title, ownerID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying song by group and title", "error", err, "group", group, "song", title)
+		return nil, err
+	}
+
+	return song, nil
+}
+
+// GetFieldProvenance возвращает происхождение полей песни, ключ — имя поля.
+func (r *SongRepositoryImpl) GetFieldProvenance(ctx context.Context, songID int) (_ map[string]entities.FieldProvenance, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.GetFieldProvenance", attribute.Int("song.id", songID))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT field, source, fetched_at, manually_overridden, updated_at
+		FROM song_field_provenance
+		WHERE song_id = $1
+	`
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, songID)
+	if err != nil {
+		r.log(ctx).Error("error querying field provenance", "error", err, "songID", songID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	provenance := make(map[string]entities.FieldProvenance)
+	for rows.Next() {
+		var field string
+		var p entities.FieldProvenance
+		if err := rows.Scan(&field, &p.Source, &p.FetchedAt, &p.ManuallyOverridden, &p.UpdatedAt); err != nil {
+			r.log(ctx).Error("error scanning field provenance row", "error", err)
+			return nil, err
+		}
+		provenance[field] = p
+	}
+
+	return provenance, rows.Err()
+}
+
+// SaveFieldProvenance записывает происхождение значения поля песни.
+func (r *SongRepositoryImpl) SaveFieldProvenance(ctx context.Context, songID int, field string, provenance entities.FieldProvenance) (err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "SongRepository.SaveFieldProvenance", attribute.Int("song.id", songID))
+	defer tracing.End(span, &err)
+
+	query := `
+		INSERT INTO song_field_provenance (song_id, field, source, fetched_at, manually_overridden, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (song_id, field)
+		DO UPDATE SET source = EXCLUDED.source,
+		              fetched_at = EXCLUDED.fetched_at,
+		              manually_overridden = EXCLUDED.manually_overridden,
+		              updated_at = NOW()
+	`
+
+	_, err = r.db.Conn(ctx).Exec(ctx, query, songID, field, provenance.Source, provenance.FetchedAt, provenance.ManuallyOverridden)
+	if err != nil {
+		r.log(ctx).Error("error saving field provenance", "error", err, "songID", songID, "field", field)
+	}
+	return err
+}
+
+func scanSong(row pgx.Row) (*entities.Song, error) {
+	var song entities.Song
+	if err := row.Scan(&song.ID, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link, &song.EnrichedAt, &song.OwnerID, &song.Visibility); err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+func scanSongs(rows pgx.Rows) ([]entities.Song, error) {
+	var songs []entities.Song
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, *song)
+	}
+	return songs, rows.Err()
+}
+
+func (r *SongRepositoryImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, r.logger)
+}
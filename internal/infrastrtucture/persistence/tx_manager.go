@@ -0,0 +1,9 @@
+package persistence
+
+import "context"
+
+// TxManager объединяет вызовы репозиториев в одну транзакцию: репозитории, вызванные с ctx,
+// который InTx передаёт в fn, выполняют запросы внутри неё. Реализуется database.DB.
+type TxManager interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"context"
+	"effictiveMobile/internal/domain/entities"
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"log/slog"
+)
+
+var ErrUsernameTaken = errors.New("username is already taken")
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *entities.User) error
+	GetUserByUsername(ctx context.Context, username string) (*entities.User, error)
+	GetUserByID(ctx context.Context, id int) (*entities.User, error)
+	SetUserRole(ctx context.Context, id int, role string) error
+}
+
+const userColumns = "id, username, password_hash, role, created_at"
+
+// uniqueViolation — код ошибки Postgres при нарушении уникального индекса.
+const uniqueViolation = "23505"
+
+type UserRepositoryImpl struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+func NewUserRepository(db *database.DB, logger *slog.Logger) *UserRepositoryImpl {
+	return &UserRepositoryImpl{
+		db:     db,
+		logger: logger.With(slog.String("repository", "UserRepository")),
+	}
+}
+
+// CreateUser сохраняет нового пользователя. Если имя занято, возвращается ErrUsernameTaken.
+func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *entities.User) error {
+	query := `
+		INSERT INTO users (username, password_hash, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.Conn(ctx).QueryRow(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return ErrUsernameTaken
+		}
+		r.log(ctx).Error("error creating user", "error", err, "username", user.Username)
+	}
+	return err
+}
+
+// GetUserByUsername возвращает пользователя по имени.
+func (r *UserRepositoryImpl) GetUserByUsername(ctx context.Context, username string) (*entities.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE username = $1"
+
+	user, err := scanUser(r.db.Conn(ctx).QueryRow(ctx, query, username))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying user by username", "error", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetUserByID возвращает пользователя по ID.
+func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int) (*entities.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1"
+
+	user, err := scanUser(r.db.Conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("error querying user by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetUserRole меняет роль пользователя.
+func (r *UserRepositoryImpl) SetUserRole(ctx context.Context, id int, role string) error {
+	query := "UPDATE users SET role = $1 WHERE id = $2"
+
+	_, err := r.db.Conn(ctx).Exec(ctx, query, role, id)
+	if err != nil {
+		r.log(ctx).Error("error updating user role", "error", err, "id", id)
+	}
+	return err
+}
+
+func scanUser(row pgx.Row) (*entities.User, error) {
+	var u entities.User
+	if err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepositoryImpl) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, r.logger)
+}
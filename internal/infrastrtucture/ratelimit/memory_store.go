@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит счётчики в памяти процесса; подходит для одного инстанса.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type counter struct {
+	windowStart time.Time
+	window      time.Duration
+	count       int
+}
+
+// sweepInterval задаёт, как часто из памяти удаляются счётчики закончившихся окон.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:  make(map[string]*counter),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit int, window time.Duration) (Result, error) {
+	now := s.now()
+	windowStart := now.Truncate(window)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	c, ok := s.counters[key]
+	if !ok || !c.windowStart.Equal(windowStart) {
+		c = &counter{windowStart: windowStart, window: window}
+		s.counters[key] = c
+	}
+	c.count++
+
+	return newResult(c.count, limit, windowStart, window), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, c := range s.counters {
+		if !now.Before(c.windowStart.Add(c.window)) {
+			delete(s.counters, key)
+		}
+	}
+	s.lastSweep = now
+}
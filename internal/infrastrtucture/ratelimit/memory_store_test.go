@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestMemoryStore(now *time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = func() time.Time { return *now }
+	s.lastSweep = *now
+	return s
+}
+
+func TestMemoryStoreCountsWithinWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 10, 0, time.UTC)
+	s := newTestMemoryStore(&now)
+
+	for i, want := range []Result{
+		{Allowed: true, Limit: 2, Remaining: 1},
+		{Allowed: true, Limit: 2, Remaining: 0},
+		{Allowed: false, Limit: 2, Remaining: 0},
+	} {
+		got, err := s.Take(context.Background(), "client", 2, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.Reset = time.Date(2026, 1, 1, 12, 1, 0, 0, time.UTC)
+		if got != want {
+			t.Errorf("request %d: got %+v, want %+v", i+1, got, want)
+		}
+	}
+}
+
+func TestMemoryStoreStartsNewWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 59, 0, time.UTC)
+	s := newTestMemoryStore(&now)
+
+	for range 3 {
+		if _, err := s.Take(context.Background(), "client", 2, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(time.Second)
+	got, err := s.Take(context.Background(), "client", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Allowed || got.Remaining != 1 {
+		t.Errorf("first request of a new window: got %+v", got)
+	}
+	if want := time.Date(2026, 1, 1, 12, 2, 0, 0, time.UTC); !got.Reset.Equal(want) {
+		t.Errorf("reset = %v, want %v", got.Reset, want)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestMemoryStore(&now)
+
+	if _, err := s.Take(context.Background(), "first", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Take(context.Background(), "second", 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Allowed {
+		t.Error("another key must have its own quota")
+	}
+}
+
+func TestMemoryStoreSweepsFinishedWindows(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestMemoryStore(&now)
+
+	if _, err := s.Take(context.Background(), "idle", 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(sweepInterval)
+	if _, err := s.Take(context.Background(), "active", 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.counters["idle"]; ok {
+		t.Error("counter of a finished window must be swept")
+	}
+	if _, ok := s.counters["active"]; !ok {
+		t.Error("counter of the current window must be kept")
+	}
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"effictiveMobile/pkg/database"
+	"effictiveMobile/pkg/requestctx"
+	"log/slog"
+	"time"
+)
+
+// PostgresStore хранит счётчики в Postgres, чтобы квоты были общими для всех инстансов сервиса.
+type PostgresStore struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+// retention — сколько хранятся счётчики закончившихся окон перед удалением.
+const retention = time.Hour
+
+func NewPostgresStore(db *database.DB, logger *slog.Logger) *PostgresStore {
+	return &PostgresStore{
+		db:     db,
+		logger: logger.With("component", "RateLimitPostgresStore"),
+	}
+}
+
+// Take считает окно по часам базы, а не инстанса: иначе при расхождении часов
+// инстансы писали бы запросы одного клиента в разные окна.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	query := `
+		INSERT INTO rate_limit_counters (key, window_start, count)
+		VALUES ($1, date_bin(make_interval(secs => $2), now(), TIMESTAMPTZ 'epoch'), 1)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING window_start, count
+	`
+
+	var windowStart time.Time
+	var count int
+	if err := s.db.Conn(ctx).QueryRow(ctx, query, key, window.Seconds()).Scan(&windowStart, &count); err != nil {
+		return Result{}, err
+	}
+
+	return newResult(count, limit, windowStart, window), nil
+}
+
+// Run периодически удаляет старые счётчики до отмены ctx.
+func (s *PostgresStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(retention / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			query := "DELETE FROM rate_limit_counters WHERE window_start < now() - make_interval(secs => $1)"
+			if _, err := s.db.Conn(ctx).Exec(ctx, query, retention.Seconds()); err != nil && ctx.Err() == nil {
+				s.log(ctx).Error("error deleting expired rate limit counters", "error", err)
+			}
+		}
+	}
+}
+
+func (s *PostgresStore) log(ctx context.Context) *slog.Logger {
+	return requestctx.Logger(ctx, s.logger)
+}
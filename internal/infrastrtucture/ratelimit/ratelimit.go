@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Result описывает состояние квоты клиента после очередного запроса.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Store считает запросы клиентов в фиксированных окнах.
+type Store interface {
+	// Take учитывает запрос клиента key и сообщает, укладывается ли он в limit запросов за window.
+	Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+func newResult(count, limit int, windowStart time.Time, window time.Duration) Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     windowStart.Add(window),
+	}
+}
@@ -0,0 +1,172 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Options задаёт, куда и какую долю трасс отправлять.
+type Options struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+// Provider — провайдер трасс и пропагатор контекста одного экземпляра сервиса.
+// Компоненты получают его из context.Context (см. WithContext), а не из глобальных переменных otel,
+// поэтому несколько экземпляров в одном процессе не перезаписывают настройки трассировки друг друга.
+type Provider struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	shutdown       func(context.Context) error
+}
+
+// disabled используется, если в контексте нет провайдера: спаны не создаются,
+// но контекст трассы вызывающей стороны по-прежнему передаётся дальше.
+var disabled = &Provider{
+	tracerProvider: noop.NewTracerProvider(),
+	propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	shutdown:       func(context.Context) error { return nil },
+}
+
+type providerKey struct{}
+
+// Setup создаёт провайдер трасс с W3C Trace Context для входящих и исходящих запросов.
+// С экспортером none спаны не создаются, но контекст трассы по-прежнему передаётся дальше.
+func Setup(ctx context.Context, opts Options) (*Provider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return disabled, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOpts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+
+	return &Provider{
+		tracerProvider: provider,
+		propagator:     disabled.propagator,
+		shutdown:       provider.Shutdown,
+	}, nil
+}
+
+// NewProvider оборачивает уже настроенный провайдер трасс, например SDK встраивающего приложения
+// или провайдер с tracetest.SpanRecorder в тестах. Останавливает tp тот, кто его создал.
+func NewProvider(tp trace.TracerProvider) *Provider {
+	return &Provider{
+		tracerProvider: tp,
+		propagator:     disabled.propagator,
+		shutdown:       func(context.Context) error { return nil },
+	}
+}
+
+// WithContext возвращает ctx, из которого Start, StartClient, Inject и Extract возьмут этот провайдер.
+func (p *Provider) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, providerKey{}, p)
+}
+
+// Shutdown отправляет накопленные спаны и останавливает экспорт.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+func fromContext(ctx context.Context) *Provider {
+	if p, ok := ctx.Value(providerKey{}).(*Provider); ok {
+		return p
+	}
+	return disabled
+}
+
+// Start открывает спан от имени компонента tracerName.
+func Start(ctx context.Context, tracerName, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return fromContext(ctx).tracerProvider.Tracer(tracerName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// StartClient открывает спан исходящего вызова во внешнюю систему.
+func StartClient(ctx context.Context, tracerName, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return fromContext(ctx).tracerProvider.Tracer(tracerName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// StartServer открывает спан обработки входящего запроса.
+func StartServer(ctx context.Context, tracerName, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return fromContext(ctx).tracerProvider.Tracer(tracerName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// Inject записывает контекст трассы из ctx в заголовки исходящего запроса (traceparent, baggage).
+func Inject(ctx context.Context, header http.Header) {
+	fromContext(ctx).propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract достаёт контекст трассы вызывающей стороны из заголовков входящего запроса.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return fromContext(ctx).propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// End закрывает спан и помечает его ошибкой, если *err не nil.
+// Удобно вызывать через defer с именованным результатом: defer tracing.End(span, &err).
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		RecordError(span, *err)
+	}
+	span.End()
+}
+
+// RecordError помечает спан ошибкой. Отмена запроса клиентом записывается как событие, а не как сбой.
+func RecordError(span trace.Span, err error) {
+	if errors.Is(err, context.Canceled) {
+		span.AddEvent("canceled")
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
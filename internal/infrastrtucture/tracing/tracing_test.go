@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecordingProvider() (*Provider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return NewProvider(tp), recorder
+}
+
+func TestProvidersDoNotShareSpans(t *testing.T) {
+	first, firstSpans := newRecordingProvider()
+	second, secondSpans := newRecordingProvider()
+
+	_, span := Start(first.WithContext(context.Background()), "test", "first")
+	span.End()
+	_, span = Start(second.WithContext(context.Background()), "test", "second")
+	span.End()
+
+	if got := firstSpans.Ended(); len(got) != 1 || got[0].Name() != "first" {
+		t.Errorf("first provider recorded %d spans, want only \"first\"", len(got))
+	}
+	if got := secondSpans.Ended(); len(got) != 1 || got[0].Name() != "second" {
+		t.Errorf("second provider recorded %d spans, want only \"second\"", len(got))
+	}
+}
+
+func TestStartWithoutProviderIsNoop(t *testing.T) {
+	_, span := Start(context.Background(), "test", "span")
+	defer span.End()
+
+	if span.IsRecording() {
+		t.Error("span must not be recorded without a provider in the context")
+	}
+}
+
+func TestTraceContextPropagatesWithoutProvider(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	incoming := http.Header{}
+	incoming.Set("traceparent", traceparent)
+	ctx := Extract(context.Background(), incoming)
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	if got := outgoing.Get("traceparent"); got != traceparent {
+		t.Errorf("traceparent = %q, want %q", got, traceparent)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS song_change_proposals;
+DROP INDEX IF EXISTS songs_enriched_at_idx;
+ALTER TABLE songs DROP COLUMN IF EXISTS enriched_at;
@@ -0,0 +1,17 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enriched_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS songs_enriched_at_idx ON songs (enriched_at);
+
+CREATE TABLE IF NOT EXISTS song_change_proposals (
+                                     id SERIAL PRIMARY KEY,
+                                     song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+                                     field VARCHAR(50) NOT NULL,
+                                     current_value TEXT NOT NULL DEFAULT '',
+                                     proposed_value TEXT NOT NULL DEFAULT '',
+                                     status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                                     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                     resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS song_change_proposals_pending_idx
+    ON song_change_proposals (song_id, field) WHERE status = 'pending';
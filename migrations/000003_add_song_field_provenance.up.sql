@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS song_field_provenance (
+                                     song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+                                     field VARCHAR(50) NOT NULL,
+                                     source VARCHAR(50) NOT NULL,
+                                     fetched_at TIMESTAMPTZ,
+                                     manually_overridden BOOLEAN NOT NULL DEFAULT FALSE,
+                                     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                     PRIMARY KEY (song_id, field)
+);
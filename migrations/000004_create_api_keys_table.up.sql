@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+                                     id SERIAL PRIMARY KEY,
+                                     name VARCHAR(255) NOT NULL,
+                                     key_prefix VARCHAR(16) NOT NULL,
+                                     key_hash CHAR(64) NOT NULL UNIQUE,
+                                     scopes TEXT[] NOT NULL DEFAULT '{}',
+                                     expires_at TIMESTAMPTZ,
+                                     last_used_at TIMESTAMPTZ,
+                                     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                     revoked_at TIMESTAMPTZ
+);
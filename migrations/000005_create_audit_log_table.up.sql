@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     actor_type VARCHAR(50) NOT NULL,
+                                     actor_id VARCHAR(255) NOT NULL,
+                                     actor_name VARCHAR(255) NOT NULL DEFAULT '',
+                                     action VARCHAR(100) NOT NULL,
+                                     entity_type VARCHAR(50) NOT NULL,
+                                     entity_id VARCHAR(255) NOT NULL,
+                                     details JSONB NOT NULL DEFAULT '{}',
+                                     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+-- Журнал только пополняется: изменение и удаление записей запрещены.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
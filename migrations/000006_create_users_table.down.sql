@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS songs_owner_id_idx;
+ALTER TABLE songs DROP COLUMN IF EXISTS visibility;
+ALTER TABLE songs DROP COLUMN IF EXISTS owner_id;
+DROP TABLE IF EXISTS users;
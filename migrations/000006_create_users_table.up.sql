@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS users (
+                                     id SERIAL PRIMARY KEY,
+                                     username VARCHAR(255) NOT NULL UNIQUE,
+                                     password_hash VARCHAR(255) NOT NULL,
+                                     role VARCHAR(20) NOT NULL DEFAULT 'viewer' CHECK (role IN ('viewer', 'editor', 'admin')),
+                                     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('private', 'shared', 'public'));
+
+CREATE INDEX IF NOT EXISTS songs_owner_id_idx ON songs (owner_id);
@@ -0,0 +1,7 @@
+-- Счётчики легко восстановимы, поэтому таблица не пишется в WAL.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_counters (
+                                     key VARCHAR(255) NOT NULL,
+                                     window_start TIMESTAMPTZ NOT NULL,
+                                     count INTEGER NOT NULL DEFAULT 0,
+                                     PRIMARY KEY (key, window_start)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS audit_log_action_idx;
+DROP INDEX IF EXISTS audit_log_actor_idx;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS client_ip;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS request_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS after_hash;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS before_hash;
@@ -0,0 +1,7 @@
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_hash CHAR(64);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_hash CHAR(64);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS client_ip VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS audit_log_action_idx ON audit_log (action);
@@ -0,0 +1 @@
+ALTER TABLE songs DROP COLUMN IF EXISTS enrich_failed_at;
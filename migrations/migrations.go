@@ -0,0 +1,7 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы они не зависели от рабочего каталога.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
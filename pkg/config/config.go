@@ -2,25 +2,325 @@ package config
 
 import (
 	"encoding/json"
-	"log/slog"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func init() {
-	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: false})).With("group", "Config")
-	if _, err := os.Stat("config.override.json"); os.IsNotExist(err) {
-		log.Error("config.override.json not found")
-	} else {
-		data, err := os.ReadFile("config.override.json")
-		if err != nil {
-			log.Error("Read config.override.json", "error", err.Error())
-			os.Exit(2)
+const (
+	// EnvPrefix — префикс переменных окружения, например SONGLIB_DATABASE_URI.
+	EnvPrefix = "SONGLIB_"
+
+	// legacyConfigFile читается, если путь к конфигу не задан ни флагом, ни SONGLIB_CONFIG.
+	legacyConfigFile = "config.override.json"
+
+	redacted = "***"
+)
+
+// Load читает конфигурацию из файла path поверх значений по умолчанию и применяет переменные окружения.
+// Пустой path означает конфигурацию только из значений по умолчанию и окружения.
+// Нужна для встраивания сервиса: результат оборачивается в NewStore и передаётся в application.Run.
+func Load(path string) (*Config, error) {
+	return build(path, nil, os.LookupEnv)
+}
+
+// Parse загружает конфигурацию по аргументам командной строки и возвращает хранилище,
+// умеющее перечитать её из тех же источников, и оставшиеся позиционные аргументы (подкоманду).
+func Parse(args []string) (*Store, []string, error) {
+	fs := flag.NewFlagSet("song_server", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a JSON or YAML config file (env "+EnvPrefix+"CONFIG)")
+
+	// Значения флагов запоминаются и применяются последними, уже после файла и окружения.
+	flagValues := map[string]string{}
+	for _, f := range fields(&Config{}) {
+		name := f.flagName()
+		fs.Func(name, "overrides "+f.envName(), func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	file := *configPath
+	if file == "" {
+		file = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if file == "" {
+		if _, err := os.Stat(legacyConfigFile); err == nil {
+			file = legacyConfigFile
+		}
+	}
+
+	store := &Store{
+		file: file,
+		load: func() (*Config, error) {
+			return build(file, flagValues, os.LookupEnv)
+		},
+	}
+	cfg, err := store.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	store.current.Store(cfg)
+
+	return store, fs.Args(), nil
+}
+
+// build применяет источники по возрастанию приоритета, каждый следующий перекрывает предыдущий:
+//  1. значения по умолчанию (defaults);
+//  2. файл JSON или YAML из --config или SONGLIB_CONFIG, иначе config.override.json, если он есть;
+//  3. переменные окружения SONGLIB_<СЕКЦИЯ>_<ПОЛЕ>;
+//  4. флаги командной строки --<секция>.<поле>.
+func build(file string, flagValues map[string]string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := defaults()
+
+	if file != "" {
+		if err := loadFile(file, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range fields(&cfg) {
+		if value, ok := lookupEnv(f.envName()); ok {
+			if err := f.set(value); err != nil {
+				return nil, fmt.Errorf("env %s: %w", f.envName(), err)
+			}
+		}
+	}
+
+	for _, f := range fields(&cfg) {
+		if value, ok := flagValues[f.flagName()]; ok {
+			if err := f.set(value); err != nil {
+				return nil, fmt.Errorf("flag --%s: %w", f.flagName(), err)
+			}
 		}
+	}
+
+	return &cfg, nil
+}
+
+// defaults — единственное место, где заданы значения по умолчанию: аксессоры Config их не дублируют,
+// а обнулённые в файле или окружении значения отклоняет Validate.
+func defaults() Config {
+	return Config{
+		Database: dbConfig{
+			MaxConns:          runtime.NumCPU(),
+			MaxConnLifetime:   Duration(time.Hour),
+			MaxConnIdleTime:   Duration(30 * time.Minute),
+			HealthCheckPeriod: Duration(time.Minute),
+			ReconnectInterval: Duration(5 * time.Second),
+			ConnectTimeout:    Duration(time.Minute),
+			AutoMigrate:       true,
+		},
+		Server: serverConfig{
+			ServerUrl:       "http://localhost:8001",
+			Port:            "8001",
+			ReadTimeout:     Duration(5 * time.Second),
+			WriteTimeout:    Duration(10 * time.Second),
+			IdleTimeout:     Duration(time.Minute),
+			MaxHeaderBytes:  1 << 20,
+			ShutdownTimeout: Duration(5 * time.Second),
+			RequestTimeout:  Duration(8 * time.Second),
+		},
+		Credentials: credentials{
+			JWT: jwtConfig{
+				ScopeClaim:      "scope",
+				UserIDClaim:     "sub",
+				RefreshInterval: Duration(time.Hour),
+			},
+			UserTokens: userTokensConfig{
+				Issuer: "song-library",
+				TTL:    Duration(time.Hour),
+			},
+			HMAC: hmacConfig{
+				MaxSkew:        Duration(5 * time.Minute),
+				NonceCacheSize: 100000,
+			},
+		},
+		External: external{
+			Timeout:        Duration(10 * time.Second),
+			RateLimit:      5,
+			Burst:          1,
+			MaxConcurrency: 4,
+
+			CircuitFailures: 5,
+			CircuitCooldown: Duration(30 * time.Second),
+		},
+		Enrichment: enrichment{
+			Interval:  Duration(time.Hour),
+			MaxAge:    Duration(30 * 24 * time.Hour),
+			BatchSize: 50,
+		},
+		RateLimit: rateLimit{
+			Store:   "memory",
+			Default: RateLimitQuota{Requests: 100, Window: Duration(time.Minute)},
+		},
+		Log: logConfig{
+			Level:  "info",
+			Format: "json",
+			Sampling: logSampling{
+				Initial:    10,
+				Thereafter: 100,
+				Interval:   Duration(time.Minute),
+			},
+		},
+		Tracing: tracing{
+			Exporter:    "none",
+			ServiceName: "song-library",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// loadFile читает конфиг поверх уже заполненных значений; формат определяется по расширению.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config %s: %w", path, err)
+	}
 
-		err = json.Unmarshal(data, &Config)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("config %s: unsupported format, use .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Print выводит конфигурацию в JSON с замаскированными секретами.
+func (c *Config) Print(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Redacted())
+}
+
+// Redacted возвращает копию конфигурации, в которой секреты и пароль в строке подключения к БД замаскированы.
+func (c Config) Redacted() Config {
+	c.Database.URI = redactURI(c.Database.URI)
+	c.Credentials.ApiKey = redactSecret(c.Credentials.ApiKey)
+	c.Credentials.UserTokens.SigningKey = redactSecret(c.Credentials.UserTokens.SigningKey)
+
+	clients := make([]HMACClient, len(c.Credentials.HMAC.Clients))
+	for i, client := range c.Credentials.HMAC.Clients {
+		client.Secret = redactSecret(client.Secret)
+		clients[i] = client
+	}
+	c.Credentials.HMAC.Clients = clients
+
+	return c
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURI маскирует пароль в строке подключения к БД: в URL postgres:// заменяются пароль
+// и параметр password. config print показывает и конфигурацию, которую Validate отклонил бы,
+// поэтому всё, что не является URL postgres://, маскируется целиком.
+func redactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		return redactSecret(uri)
+	}
+
+	if query := parsed.Query(); query.Has("password") {
+		query.Set("password", "xxxxx")
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.Redacted()
+}
+
+// field — лист дерева конфигурации, доступный для переопределения из окружения и флагов.
+type field struct {
+	path  []string
+	value reflect.Value
+}
+
+func (f field) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.Join(f.path, "_"))
+}
+
+func (f field) flagName() string {
+	return strings.Join(f.path, ".")
+}
+
+// set разбирает строковое значение по типу поля; списки и словари задаются в JSON.
+func (f field) set(raw string) error {
+	v := f.value
+	switch {
+	case v.Type() == reflect.TypeOf(Duration(0)):
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(parsed))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
 		if err != nil {
-			log.Error("Unmarshal config", "error", err.Error())
-			os.Exit(2)
+			return err
+		}
+		v.SetBool(parsed)
+	case v.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(parsed))
+	case v.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(parsed)
+	case v.Kind() == reflect.Slice, v.Kind() == reflect.Map:
+		if err := json.Unmarshal([]byte(raw), v.Addr().Interface()); err != nil {
+			return fmt.Errorf("expected JSON value: %w", err)
+		}
+	default:
+		return errors.New("unsupported field type " + v.Type().String())
+	}
+	return nil
+}
+
+// fields обходит конфигурацию и возвращает её листья с путями из json-тегов.
+func fields(cfg *Config) []field {
+	var result []field
+	var walk func(v reflect.Value, path []string)
+	walk = func(v reflect.Value, path []string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			fieldPath := append(append([]string{}, path...), name)
+			if t.Field(i).Type.Kind() == reflect.Struct {
+				walk(v.Field(i), fieldPath)
+				continue
+			}
+			result = append(result, field{path: fieldPath, value: v.Field(i)})
 		}
 	}
+	walk(reflect.ValueOf(cfg).Elem(), nil)
+	return result
 }
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactURI(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{name: "empty", uri: "", want: ""},
+		{
+			name: "url with password",
+			uri:  "postgres://songs:hunter2@db:5432/songs?sslmode=disable",
+			want: "postgres://songs:xxxxx@db:5432/songs?sslmode=disable",
+		},
+		{
+			name: "url without password",
+			uri:  "postgresql://songs@db/songs",
+			want: "postgresql://songs@db/songs",
+		},
+		{
+			name: "url with password parameter",
+			uri:  "postgres://songs@db/songs?password=hunter2&sslmode=disable",
+			want: "postgres://songs@db/songs?password=xxxxx&sslmode=disable",
+		},
+		{
+			name: "keyword dsn",
+			uri:  "host=db port=5432 user=songs password=hunter2 dbname=songs",
+			want: redacted,
+		},
+		{
+			name: "keyword dsn with quoted password",
+			uri:  "host=db password='hunter 2' dbname=songs",
+			want: redacted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactURI(tt.uri)
+			if got != tt.want {
+				t.Errorf("redactURI(%q) = %q, want %q", tt.uri, got, tt.want)
+			}
+			if strings.Contains(got, "hunter") {
+				t.Errorf("password leaked: %q", got)
+			}
+		})
+	}
+}
+
+func TestParseLayering(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{
+			name: "json",
+			file: "config.json",
+			data: `{
+				"server": {"port": "9000"},
+				"log": {"level": "debug", "format": "text"},
+				"enrichment": {"batch_size": 10}
+			}`,
+		},
+		{
+			name: "yaml",
+			file: "config.yaml",
+			data: "server:\n  port: \"9000\"\nlog:\n  level: debug\n  format: text\nenrichment:\n  batch_size: 10\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv(EnvPrefix+"LOG_LEVEL", "warn")
+			t.Setenv(EnvPrefix+"ENRICHMENT_BATCH_SIZE", "20")
+
+			store, rest, err := Parse([]string{"--config", path, "--enrichment.batch_size", "30", "migrate", "up"})
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			cfg := store.Current()
+
+			// Только значение по умолчанию.
+			if got := time.Duration(cfg.Server.ReadTimeout); got != 5*time.Second {
+				t.Errorf("server.read_timeout = %s, want default 5s", got)
+			}
+			// Файл перекрывает значение по умолчанию.
+			if cfg.Server.Port != "9000" || cfg.Log.Format != "text" {
+				t.Errorf("server.port = %q, log.format = %q, want values from file", cfg.Server.Port, cfg.Log.Format)
+			}
+			// Окружение перекрывает файл.
+			if cfg.Log.Level != "warn" {
+				t.Errorf("log.level = %q, want %q from env", cfg.Log.Level, "warn")
+			}
+			// Флаг перекрывает окружение и файл.
+			if cfg.Enrichment.BatchSize != 30 {
+				t.Errorf("enrichment.batch_size = %d, want 30 from flag", cfg.Enrichment.BatchSize)
+			}
+			if strings.Join(rest, " ") != "migrate up" {
+				t.Errorf("remaining args = %v, want [migrate up]", rest)
+			}
+		})
+	}
+}
+
+func TestParseConfigFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"port": "9100"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvPrefix+"CONFIG", path)
+
+	store, _, err := Parse(nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := store.Current().Server.Port; got != "9100" {
+		t.Errorf("server.port = %q, want value from %sCONFIG file", got, EnvPrefix)
+	}
+}
+
+func TestParseRejectsInvalidOverride(t *testing.T) {
+	t.Setenv(EnvPrefix+"ENRICHMENT_BATCH_SIZE", "many")
+
+	if _, _, err := Parse(nil); err == nil || !strings.Contains(err.Error(), EnvPrefix+"ENRICHMENT_BATCH_SIZE") {
+		t.Fatalf("got %v, want an error naming the env variable", err)
+	}
+}
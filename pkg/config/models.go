@@ -1,52 +1,462 @@
 package config
 
-type config struct {
-	Database    dbConfig     `json:"database"`
-	Server      serverConfig `json:"server"`
-	Credentials credentials  `json:"credentials"`
-	External    external     `json:"external"`
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type Config struct {
+	Database    dbConfig     `json:"database" yaml:"database"`
+	Server      serverConfig `json:"server" yaml:"server"`
+	Credentials credentials  `json:"credentials" yaml:"credentials"`
+	External    external     `json:"external" yaml:"external"`
+	Enrichment  enrichment   `json:"enrichment" yaml:"enrichment"`
+	RateLimit   rateLimit    `json:"rate_limit" yaml:"rate_limit"`
+	Log         logConfig    `json:"log" yaml:"log"`
+	Tracing     tracing      `json:"tracing" yaml:"tracing"`
+}
+
+type tracing struct {
+	Exporter    string  `json:"exporter" yaml:"exporter"`
+	Endpoint    string  `json:"endpoint" yaml:"endpoint"`
+	Insecure    bool    `json:"insecure" yaml:"insecure"`
+	ServiceName string  `json:"service_name" yaml:"service_name"`
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio"`
+}
+
+type logConfig struct {
+	Level     string `json:"level" yaml:"level"`
+	Format    string `json:"format" yaml:"format"`
+	AddSource bool   `json:"add_source" yaml:"add_source"`
+	// Packages задаёт уровень для отдельных пакетов, например {"persistence": "debug"}.
+	Packages map[string]string `json:"packages" yaml:"packages"`
+	Sampling logSampling       `json:"sampling" yaml:"sampling"`
+}
+
+type logSampling struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	Initial    int      `json:"initial" yaml:"initial"`
+	Thereafter int      `json:"thereafter" yaml:"thereafter"`
+	Interval   Duration `json:"interval" yaml:"interval"`
 }
 
 type dbConfig struct {
-	URI string `json:"uri"`
+	URI               string   `json:"uri" yaml:"uri"`
+	MaxConns          int      `json:"max_conns" yaml:"max_conns"`
+	MinConns          int      `json:"min_conns" yaml:"min_conns"`
+	MaxConnLifetime   Duration `json:"max_conn_lifetime" yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   Duration `json:"max_conn_idle_time" yaml:"max_conn_idle_time"`
+	HealthCheckPeriod Duration `json:"health_check_period" yaml:"health_check_period"`
+	ReconnectInterval Duration `json:"reconnect_interval" yaml:"reconnect_interval"`
+	ConnectTimeout    Duration `json:"connect_timeout" yaml:"connect_timeout"`
+	AutoMigrate       bool     `json:"auto_migrate" yaml:"auto_migrate"`
 }
 
 type serverConfig struct {
-	ServerUrl string `json:"server_url"`
-	Host      string `json:"host"`
-	Port      string `json:"port"`
+	ServerUrl string `json:"server_url" yaml:"server_url"`
+	Host      string `json:"host" yaml:"host"`
+	Port      string `json:"port" yaml:"port"`
+
+	ReadTimeout     Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	MaxHeaderBytes  int      `json:"max_header_bytes" yaml:"max_header_bytes"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	DrainDelay      Duration `json:"drain_delay" yaml:"drain_delay"`
+
+	// RouteTimeouts переопределяет RequestTimeout для отдельных маршрутов; ключ — "GET /api/v1/songs/{id}".
+	RequestTimeout Duration            `json:"request_timeout" yaml:"request_timeout"`
+	RouteTimeouts  map[string]Duration `json:"route_timeouts" yaml:"route_timeouts"`
 }
 
 type credentials struct {
-	ApiKey string `json:"api_key"`
+	ApiKey     string           `json:"api_key" yaml:"api_key"`
+	JWT        jwtConfig        `json:"jwt" yaml:"jwt"`
+	UserTokens userTokensConfig `json:"user_tokens" yaml:"user_tokens"`
+	HMAC       hmacConfig       `json:"hmac" yaml:"hmac"`
+}
+
+type hmacConfig struct {
+	Clients        []HMACClient `json:"clients" yaml:"clients"`
+	MaxSkew        Duration     `json:"max_skew" yaml:"max_skew"`
+	NonceCacheSize int          `json:"nonce_cache_size" yaml:"nonce_cache_size"`
+}
+
+// HMACClient описывает сервер-клиента, подписывающего запросы общим секретом.
+type HMACClient struct {
+	ID     string   `json:"id" yaml:"id"`
+	Secret string   `json:"secret" yaml:"secret"`
+	Scopes []string `json:"scopes" yaml:"scopes"`
+}
+
+type userTokensConfig struct {
+	SigningKey string   `json:"signing_key" yaml:"signing_key"`
+	Issuer     string   `json:"issuer" yaml:"issuer"`
+	TTL        Duration `json:"ttl" yaml:"ttl"`
+}
+
+type jwtConfig struct {
+	Enabled         bool     `json:"enabled" yaml:"enabled"`
+	JWKSURL         string   `json:"jwks_url" yaml:"jwks_url"`
+	JWKSFile        string   `json:"jwks_file" yaml:"jwks_file"`
+	Issuer          string   `json:"issuer" yaml:"issuer"`
+	Audience        string   `json:"audience" yaml:"audience"`
+	ScopeClaim      string   `json:"scope_claim" yaml:"scope_claim"`
+	UserIDClaim     string   `json:"user_id_claim" yaml:"user_id_claim"`
+	RefreshInterval Duration `json:"refresh_interval" yaml:"refresh_interval"`
 }
 
 type external struct {
-	ExtApiUrl string `json:"ext_api_url"`
+	ExtApiUrl      string   `json:"ext_api_url" yaml:"ext_api_url"`
+	Timeout        Duration `json:"timeout" yaml:"timeout"`
+	RateLimit      float64  `json:"rate_limit" yaml:"rate_limit"`
+	Burst          int      `json:"burst" yaml:"burst"`
+	MaxConcurrency int      `json:"max_concurrency" yaml:"max_concurrency"`
+
+	CircuitFailures int      `json:"circuit_failures" yaml:"circuit_failures"`
+	CircuitCooldown Duration `json:"circuit_cooldown" yaml:"circuit_cooldown"`
 }
 
-var Config config
+type enrichment struct {
+	Enabled   bool     `json:"enabled" yaml:"enabled"`
+	Interval  Duration `json:"interval" yaml:"interval"`
+	MaxAge    Duration `json:"max_age" yaml:"max_age"`
+	BatchSize int      `json:"batch_size" yaml:"batch_size"`
+}
+
+type rateLimit struct {
+	Enabled bool                      `json:"enabled" yaml:"enabled"`
+	Store   string                    `json:"store" yaml:"store"`
+	Default RateLimitQuota            `json:"default" yaml:"default"`
+	Scopes  map[string]RateLimitQuota `json:"scopes" yaml:"scopes"`
+}
+
+// RateLimitQuota — сколько запросов клиент может сделать за окно.
+type RateLimitQuota struct {
+	Requests int      `json:"requests" yaml:"requests"`
+	Window   Duration `json:"window" yaml:"window"`
+}
 
-func (c *config) DatabaseURI() string {
+// Duration позволяет задавать интервалы в конфиге строкой вида "10m" или "24h".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (c *Config) DatabaseURI() string {
 	return c.Database.URI
 }
 
-func (c *config) ServerURI() string {
+// DatabaseMaxConns возвращает размер пула соединений.
+func (c *Config) DatabaseMaxConns() int {
+	return c.Database.MaxConns
+}
+
+func (c *Config) DatabaseMinConns() int {
+	return c.Database.MinConns
+}
+
+// DatabaseMaxConnLifetime задаёт, через сколько соединение закрывается и открывается заново.
+func (c *Config) DatabaseMaxConnLifetime() time.Duration {
+	return time.Duration(c.Database.MaxConnLifetime)
+}
+
+func (c *Config) DatabaseMaxConnIdleTime() time.Duration {
+	return time.Duration(c.Database.MaxConnIdleTime)
+}
+
+func (c *Config) DatabaseHealthCheckPeriod() time.Duration {
+	return time.Duration(c.Database.HealthCheckPeriod)
+}
+
+// DatabaseReconnectInterval задаёт, как часто проверяется соединение с базой.
+func (c *Config) DatabaseReconnectInterval() time.Duration {
+	return time.Duration(c.Database.ReconnectInterval)
+}
+
+// DatabaseConnectTimeout ограничивает ожидание доступности базы при старте.
+func (c *Config) DatabaseConnectTimeout() time.Duration {
+	return time.Duration(c.Database.ConnectTimeout)
+}
+
+// DatabaseAutoMigrate сообщает, применять ли миграции при старте сервиса.
+// Если выключено, миграции запускаются отдельно командой song_server migrate up.
+func (c *Config) DatabaseAutoMigrate() bool {
+	return c.Database.AutoMigrate
+}
+
+func (c *Config) ServerURI() string {
 	return c.Server.ServerUrl
 }
 
-func (c *config) ServerHost() string {
+func (c *Config) ServerHost() string {
 	return c.Server.Host
 }
 
-func (c *config) ServerPort() string {
+func (c *Config) ServerPort() string {
 	return c.Server.Port
 }
 
-func (c *config) ApiKey() string {
+func (c *Config) ServerReadTimeout() time.Duration {
+	return time.Duration(c.Server.ReadTimeout)
+}
+
+func (c *Config) ServerWriteTimeout() time.Duration {
+	return time.Duration(c.Server.WriteTimeout)
+}
+
+// ServerIdleTimeout задаёт, сколько держать keep-alive соединение без запросов.
+func (c *Config) ServerIdleTimeout() time.Duration {
+	return time.Duration(c.Server.IdleTimeout)
+}
+
+func (c *Config) ServerMaxHeaderBytes() int {
+	return c.Server.MaxHeaderBytes
+}
+
+// ServerShutdownTimeout ограничивает ожидание завершения активных запросов при остановке.
+func (c *Config) ServerShutdownTimeout() time.Duration {
+	return time.Duration(c.Server.ShutdownTimeout)
+}
+
+// ServerDrainDelay — сколько /readyz отвечает ошибкой перед остановкой сервера,
+// чтобы балансировщик успел перестать присылать запросы.
+func (c *Config) ServerDrainDelay() time.Duration {
+	return time.Duration(c.Server.DrainDelay)
+}
+
+// ServerRequestTimeout возвращает дедлайн обработки запроса: значение для маршрута
+// из server.route_timeouts, а если его нет — server.request_timeout.
+func (c *Config) ServerRequestTimeout(method, route string) time.Duration {
+	if timeout, ok := c.Server.RouteTimeouts[method+" "+route]; ok && timeout > 0 {
+		return time.Duration(timeout)
+	}
+	return time.Duration(c.Server.RequestTimeout)
+}
+
+func (c *Config) ApiKey() string {
 	return c.Credentials.ApiKey
 }
 
-func (c *config) ExternalApiUrl() string {
+func (c *Config) JWTEnabled() bool {
+	return c.Credentials.JWT.Enabled
+}
+
+func (c *Config) JWKSURL() string {
+	return c.Credentials.JWT.JWKSURL
+}
+
+func (c *Config) JWKSFile() string {
+	return c.Credentials.JWT.JWKSFile
+}
+
+func (c *Config) JWTIssuer() string {
+	return c.Credentials.JWT.Issuer
+}
+
+func (c *Config) JWTAudience() string {
+	return c.Credentials.JWT.Audience
+}
+
+// JWTScopeClaim возвращает claim со скоупами: строка через пробел или массив строк.
+func (c *Config) JWTScopeClaim() string {
+	return c.Credentials.JWT.ScopeClaim
+}
+
+func (c *Config) JWTUserIDClaim() string {
+	return c.Credentials.JWT.UserIDClaim
+}
+
+// JWKSRefreshInterval задаёт, как часто перечитывается JWKS по URL.
+func (c *Config) JWKSRefreshInterval() time.Duration {
+	return time.Duration(c.Credentials.JWT.RefreshInterval)
+}
+
+// UserTokenSigningKey возвращает ключ подписи токенов пользователей; пустой ключ отключает вход пользователей.
+func (c *Config) UserTokenSigningKey() string {
+	return c.Credentials.UserTokens.SigningKey
+}
+
+func (c *Config) UserTokenIssuer() string {
+	return c.Credentials.UserTokens.Issuer
+}
+
+func (c *Config) UserTokenTTL() time.Duration {
+	return time.Duration(c.Credentials.UserTokens.TTL)
+}
+
+func (c *Config) HMACClients() []HMACClient {
+	return c.Credentials.HMAC.Clients
+}
+
+// HMACMaxSkew задаёт допустимое расхождение часов клиента и сервера.
+func (c *Config) HMACMaxSkew() time.Duration {
+	return time.Duration(c.Credentials.HMAC.MaxSkew)
+}
+
+func (c *Config) HMACNonceCacheSize() int {
+	return c.Credentials.HMAC.NonceCacheSize
+}
+
+func (c *Config) ExternalApiUrl() string {
 	return c.External.ExtApiUrl
 }
+
+// ExternalTimeout ограничивает длительность одного запроса к внешнему API.
+func (c *Config) ExternalTimeout() time.Duration {
+	return time.Duration(c.External.Timeout)
+}
+
+// ExternalRateLimit возвращает допустимое число запросов к внешнему API в секунду.
+func (c *Config) ExternalRateLimit() float64 {
+	return c.External.RateLimit
+}
+
+func (c *Config) ExternalBurst() int {
+	return c.External.Burst
+}
+
+func (c *Config) ExternalMaxConcurrency() int {
+	return c.External.MaxConcurrency
+}
+
+// ExternalCircuitFailures — сколько ошибок внешнего API подряд открывают предохранитель.
+func (c *Config) ExternalCircuitFailures() int {
+	return c.External.CircuitFailures
+}
+
+// ExternalCircuitCooldown — сколько предохранитель остаётся открытым до пробного запроса.
+func (c *Config) ExternalCircuitCooldown() time.Duration {
+	return time.Duration(c.External.CircuitCooldown)
+}
+
+func (c *Config) RateLimitEnabled() bool {
+	return c.RateLimit.Enabled
+}
+
+// RateLimitStore возвращает хранилище счётчиков: memory или postgres для нескольких инстансов.
+func (c *Config) RateLimitStore() string {
+	return c.RateLimit.Store
+}
+
+// RateLimitQuota возвращает квоту для скоупа, а если она не задана — квоту по умолчанию.
+func (c *Config) RateLimitQuota(scope string) (int, time.Duration) {
+	quota, ok := c.RateLimit.Scopes[scope]
+	if !ok {
+		quota = c.RateLimit.Default
+	}
+
+	return quota.Requests, time.Duration(quota.Window)
+}
+
+// LogLevel возвращает уровень логирования; неизвестное значение считается info.
+func (c *Config) LogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// LogFormat возвращает формат логов: json или text.
+func (c *Config) LogFormat() string {
+	return c.Log.Format
+}
+
+func (c *Config) LogAddSource() bool {
+	return c.Log.AddSource
+}
+
+// LogPackageLevels возвращает уровни, переопределённые для пакетов; некорректные значения пропускаются.
+func (c *Config) LogPackageLevels() map[string]slog.Level {
+	levels := make(map[string]slog.Level, len(c.Log.Packages))
+	for pkg, value := range c.Log.Packages {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err == nil {
+			levels[pkg] = level
+		}
+	}
+	return levels
+}
+
+// LogSampling возвращает параметры сэмплирования повторяющихся ошибок: сколько одинаковых
+// записей писать в начале окна, каждую какую писать потом и длину окна.
+// Если сэмплирование выключено, initial равен 0.
+func (c *Config) LogSampling() (initial, thereafter int, interval time.Duration) {
+	if !c.Log.Sampling.Enabled {
+		return 0, 0, 0
+	}
+	return c.Log.Sampling.Initial, c.Log.Sampling.Thereafter, time.Duration(c.Log.Sampling.Interval)
+}
+
+// TracingExporter возвращает экспортер трасс: none, stdout или otlp.
+func (c *Config) TracingExporter() string {
+	return c.Tracing.Exporter
+}
+
+// TracingEndpoint — URL OTLP/HTTP коллектора; пустой означает стандартные переменные OTEL_EXPORTER_OTLP_*.
+func (c *Config) TracingEndpoint() string {
+	return c.Tracing.Endpoint
+}
+
+func (c *Config) TracingInsecure() bool {
+	return c.Tracing.Insecure
+}
+
+func (c *Config) TracingServiceName() string {
+	return c.Tracing.ServiceName
+}
+
+// TracingSampleRatio — доля трасс, начатых этим сервисом, которые записываются.
+func (c *Config) TracingSampleRatio() float64 {
+	return c.Tracing.SampleRatio
+}
+
+func (c *Config) EnrichmentEnabled() bool {
+	return c.Enrichment.Enabled
+}
+
+func (c *Config) EnrichmentInterval() time.Duration {
+	return time.Duration(c.Enrichment.Interval)
+}
+
+func (c *Config) EnrichmentMaxAge() time.Duration {
+	return time.Duration(c.Enrichment.MaxAge)
+}
+
+func (c *Config) EnrichmentBatchSize() int {
+	return c.Enrichment.BatchSize
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// filePollInterval задаёт, как часто проверяется время изменения файла конфигурации.
+const filePollInterval = 2 * time.Second
+
+// reloadable — настройки, которые применяются без перезапуска: их читают из Store.Current()
+// на каждый запрос или применяет обработчик перезагрузки в application.Run.
+// Изменения остальных сохраняются в снимке, но вступят в силу только после рестарта;
+// log.format и log.add_source задаются при создании логгера и тоже требуют рестарта.
+var reloadable = map[string]bool{
+	"credentials.api_key":     true,
+	"external.ext_api_url":    true,
+	"external.timeout":        true,
+	"external.rate_limit":     true,
+	"external.burst":          true,
+	"log.level":               true,
+	"log.packages":            true,
+	"log.sampling.enabled":    true,
+	"log.sampling.initial":    true,
+	"log.sampling.thereafter": true,
+	"log.sampling.interval":   true,
+	"server.request_timeout":  true,
+	"server.route_timeouts":   true,
+}
+
+// Store хранит актуальный снимок конфигурации и умеет перечитать его из исходных источников.
+type Store struct {
+	current atomic.Pointer[Config]
+
+	// file — файл, за изменениями которого следит Watch; load — повторная загрузка всех слоёв.
+	file string
+	load func() (*Config, error)
+}
+
+// NewStore создаёт хранилище с неизменяемой конфигурацией, например загруженной через Load.
+func NewStore(cfg *Config) *Store {
+	s := &Store{}
+	s.current.Store(cfg)
+	return s
+}
+
+// Current возвращает актуальный снимок конфигурации.
+// Его читают компоненты, поддерживающие перезагрузку настроек на лету.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// Change описывает изменение одной настройки; секреты в Old и New замаскированы.
+type Change struct {
+	Path            string `json:"path"`
+	Old             string `json:"old"`
+	New             string `json:"new"`
+	RequiresRestart bool   `json:"requires_restart,omitempty"`
+}
+
+// Watch перечитывает конфигурацию по SIGHUP и при изменении файла, пока не отменён ctx.
+// Некорректная конфигурация отклоняется, и продолжает действовать предыдущая.
+// После успешной перезагрузки вызывается onReload, новый снимок доступен через s.Current.
+func (s *Store) Watch(ctx context.Context, logger *slog.Logger, onReload func()) {
+	if s.load == nil {
+		return
+	}
+	logger = logger.With("component", "ConfigWatcher")
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(filePollInterval)
+	defer ticker.Stop()
+	modTime := fileModTime(s.file)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.reload(logger, "signal", onReload)
+		case <-ticker.C:
+			if s.file == "" {
+				continue
+			}
+			if latest := fileModTime(s.file); !latest.Equal(modTime) {
+				modTime = latest
+				s.reload(logger, "file", onReload)
+			}
+		}
+	}
+}
+
+func (s *Store) reload(logger *slog.Logger, trigger string, onReload func()) {
+	cfg, err := s.load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		logger.Error("config reload rejected, keeping current config", "trigger", trigger, "error", err)
+		return
+	}
+
+	changes := Diff(s.Current(), cfg)
+	if len(changes) == 0 {
+		logger.Info("config reloaded without changes", "trigger", trigger)
+		return
+	}
+
+	s.current.Store(cfg)
+	for _, change := range changes {
+		logger.Info("config value changed", "trigger", trigger, "path", change.Path,
+			"old", change.Old, "new", change.New, "requiresRestart", change.RequiresRestart)
+	}
+
+	if onReload != nil {
+		onReload()
+	}
+}
+
+// Diff сравнивает две конфигурации и возвращает изменённые настройки.
+func Diff(old, new *Config) []Change {
+	oldRedacted, newRedacted := old.Redacted(), new.Redacted()
+	oldFields, newFields := fields(&oldRedacted), fields(&newRedacted)
+
+	// Секрет, заменённый на другой, после маскировки не отличается, поэтому сравниваются исходные значения.
+	rawOld, rawNew := *old, *new
+	rawOldFields, rawNewFields := fields(&rawOld), fields(&rawNew)
+
+	var changes []Change
+	for i := range oldFields {
+		if encode(rawOldFields[i]) == encode(rawNewFields[i]) {
+			continue
+		}
+		path := strings.Join(oldFields[i].path, ".")
+		changes = append(changes, Change{
+			Path:            path,
+			Old:             encode(oldFields[i]),
+			New:             encode(newFields[i]),
+			RequiresRestart: !reloadable[path],
+		})
+	}
+	return changes
+}
+
+func encode(f field) string {
+	data, err := json.Marshal(f.value.Interface())
+	if err != nil {
+		return "?"
+	}
+	if unquoted, err := strconv.Unquote(string(data)); err == nil {
+		return unquoted
+	}
+	return string(data)
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
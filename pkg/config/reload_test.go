@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestDiffRequiresRestart(t *testing.T) {
+	old := defaults()
+	changed := defaults()
+	changed.Log.Level = "debug"
+	changed.Log.Packages = map[string]string{"persistence": "debug"}
+	changed.Log.Sampling.Enabled = true
+	changed.Log.Sampling.Initial = 5
+	changed.Log.Format = "text"
+	changed.Log.AddSource = true
+	changed.Server.Port = "9000"
+
+	want := map[string]bool{
+		"log.level":            false,
+		"log.packages":         false,
+		"log.sampling.enabled": false,
+		"log.sampling.initial": false,
+		"log.format":           true,
+		"log.add_source":       true,
+		"server.port":          true,
+	}
+
+	changes := Diff(&old, &changed)
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for _, change := range changes {
+		requiresRestart, ok := want[change.Path]
+		if !ok {
+			t.Errorf("unexpected change %q", change.Path)
+			continue
+		}
+		if change.RequiresRestart != requiresRestart {
+			t.Errorf("%s: RequiresRestart = %v, want %v", change.Path, change.RequiresRestart, requiresRestart)
+		}
+	}
+}
+
+func TestReloadablePathsExist(t *testing.T) {
+	cfg := defaults()
+	paths := make(map[string]bool)
+	for _, f := range fields(&cfg) {
+		paths[f.flagName()] = true
+	}
+	for path := range reloadable {
+		if !paths[path] {
+			t.Errorf("reloadable path %q does not match any config field", path)
+		}
+	}
+}
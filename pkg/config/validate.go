@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minApiKeyLength     = 16
+	minSigningKeyLength = 32
+)
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные проблемы одной ошибкой.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if c.Database.URI == "" {
+		errs = append(errs, errors.New("database.uri is required"))
+	} else {
+		// Строку вида "host=… dbname=…" pgx бы принял, но golang-migrate выбирает драйвер по схеме URL.
+		parsed, err := url.Parse(c.Database.URI)
+		check(err == nil && (parsed.Scheme == "postgres" || parsed.Scheme == "postgresql"), "database.uri must be a postgres:// or postgresql:// URL")
+	}
+	check(c.Database.MaxConns > 0, "database.max_conns must be positive")
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns, "database.min_conns must be between 0 and database.max_conns")
+	checkPositive(check, "database.max_conn_lifetime", c.Database.MaxConnLifetime)
+	checkPositive(check, "database.max_conn_idle_time", c.Database.MaxConnIdleTime)
+	checkPositive(check, "database.health_check_period", c.Database.HealthCheckPeriod)
+	checkPositive(check, "database.reconnect_interval", c.Database.ReconnectInterval)
+	checkPositive(check, "database.connect_timeout", c.Database.ConnectTimeout)
+
+	check(c.Server.ServerUrl == "" || hasScheme(c.Server.ServerUrl, "http", "https"), "server.server_url must be an http(s) URL, got %q", c.Server.ServerUrl)
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port >= 1 && port <= 65535, "server.port must be a number between 1 and 65535, got %q", c.Server.Port)
+	checkPositive(check, "server.read_timeout", c.Server.ReadTimeout)
+	checkPositive(check, "server.write_timeout", c.Server.WriteTimeout)
+	checkPositive(check, "server.idle_timeout", c.Server.IdleTimeout)
+	checkPositive(check, "server.shutdown_timeout", c.Server.ShutdownTimeout)
+	check(c.Server.DrainDelay >= 0, "server.drain_delay must not be negative")
+	check(c.Server.MaxHeaderBytes > 0, "server.max_header_bytes must be positive")
+	checkPositive(check, "server.request_timeout", c.Server.RequestTimeout)
+	check(c.Server.RequestTimeout < c.Server.WriteTimeout, "server.request_timeout must be less than server.write_timeout, otherwise the timeout response can't be written")
+	routes := make([]string, 0, len(c.Server.RouteTimeouts))
+	for route := range c.Server.RouteTimeouts {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		timeout := c.Server.RouteTimeouts[route]
+		method, path, ok := strings.Cut(route, " ")
+		check(ok && method != "" && strings.ToUpper(method) == method && strings.HasPrefix(path, "/"), "server.route_timeouts: key %q must look like \"GET /api/v1/songs/{id}\"", route)
+		checkPositive(check, "server.route_timeouts."+route, timeout)
+		check(timeout < c.Server.WriteTimeout, "server.route_timeouts.%s must be less than server.write_timeout", route)
+	}
+
+	if c.Credentials.ApiKey == "" {
+		errs = append(errs, errors.New("credentials.api_key is required"))
+	} else {
+		check(len(c.Credentials.ApiKey) >= minApiKeyLength, "credentials.api_key must be at least %d characters", minApiKeyLength)
+	}
+
+	jwt := c.Credentials.JWT
+	if jwt.Enabled {
+		check((jwt.JWKSURL == "") != (jwt.JWKSFile == ""), "credentials.jwt: exactly one of jwks_url and jwks_file must be set")
+		check(jwt.JWKSURL == "" || hasScheme(jwt.JWKSURL, "http", "https"), "credentials.jwt.jwks_url must be an http(s) URL, got %q", jwt.JWKSURL)
+		checkPositive(check, "credentials.jwt.refresh_interval", jwt.RefreshInterval)
+		check(jwt.ScopeClaim != "", "credentials.jwt.scope_claim must not be empty")
+		check(jwt.UserIDClaim != "", "credentials.jwt.user_id_claim must not be empty")
+	}
+
+	if key := c.Credentials.UserTokens.SigningKey; key != "" {
+		check(len(key) >= minSigningKeyLength, "credentials.user_tokens.signing_key must be at least %d characters", minSigningKeyLength)
+		checkPositive(check, "credentials.user_tokens.ttl", c.Credentials.UserTokens.TTL)
+		check(c.Credentials.UserTokens.Issuer != "", "credentials.user_tokens.issuer must not be empty")
+	}
+
+	hmac := c.Credentials.HMAC
+	seen := make(map[string]bool, len(hmac.Clients))
+	for i, client := range hmac.Clients {
+		check(client.ID != "", "credentials.hmac.clients[%d].id is required", i)
+		check(client.Secret != "", "credentials.hmac.clients[%d].secret is required", i)
+		check(len(client.Scopes) > 0, "credentials.hmac.clients[%d].scopes must not be empty", i)
+		check(!seen[client.ID], "credentials.hmac.clients[%d]: duplicate id %q", i, client.ID)
+		seen[client.ID] = true
+	}
+	if len(hmac.Clients) > 0 {
+		checkPositive(check, "credentials.hmac.max_skew", hmac.MaxSkew)
+		check(hmac.NonceCacheSize > 0, "credentials.hmac.nonce_cache_size must be positive")
+	}
+
+	if c.External.ExtApiUrl == "" {
+		errs = append(errs, errors.New("external.ext_api_url is required"))
+	} else {
+		check(hasScheme(c.External.ExtApiUrl, "http", "https"), "external.ext_api_url must be an http(s) URL, got %q", c.External.ExtApiUrl)
+	}
+	checkPositive(check, "external.timeout", c.External.Timeout)
+	check(c.External.RateLimit > 0, "external.rate_limit must be positive")
+	check(c.External.Burst > 0, "external.burst must be positive")
+	check(c.External.MaxConcurrency > 0, "external.max_concurrency must be positive")
+	check(c.External.CircuitFailures > 0, "external.circuit_failures must be positive")
+	checkPositive(check, "external.circuit_cooldown", c.External.CircuitCooldown)
+
+	if c.Enrichment.Enabled {
+		checkPositive(check, "enrichment.interval", c.Enrichment.Interval)
+		checkPositive(check, "enrichment.max_age", c.Enrichment.MaxAge)
+		check(c.Enrichment.BatchSize > 0, "enrichment.batch_size must be positive")
+	}
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "postgres", "rate_limit.store must be memory or postgres, got %q", c.RateLimit.Store)
+		checkQuota(check, "rate_limit.default", c.RateLimit.Default)
+		scopes := make([]string, 0, len(c.RateLimit.Scopes))
+		for scope := range c.RateLimit.Scopes {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		for _, scope := range scopes {
+			checkQuota(check, "rate_limit.scopes."+scope, c.RateLimit.Scopes[scope])
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		check(c.Tracing.Endpoint == "" || hasScheme(c.Tracing.Endpoint, "http", "https"), "tracing.endpoint must be an http(s) URL, got %q", c.Tracing.Endpoint)
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter))
+	}
+	check(c.Tracing.ServiceName != "", "tracing.service_name must not be empty")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format must be json or text, got %q", c.Log.Format)
+	packages := make([]string, 0, len(c.Log.Packages))
+	for pkg := range c.Log.Packages {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	for _, pkg := range packages {
+		check(pkg != "", "log.packages: package name must not be empty")
+		check(level.UnmarshalText([]byte(c.Log.Packages[pkg])) == nil, "log.packages.%s must be debug, info, warn or error, got %q", pkg, c.Log.Packages[pkg])
+	}
+	if c.Log.Sampling.Enabled {
+		check(c.Log.Sampling.Initial > 0, "log.sampling.initial must be positive")
+		check(c.Log.Sampling.Thereafter >= 0, "log.sampling.thereafter must not be negative")
+		checkPositive(check, "log.sampling.interval", c.Log.Sampling.Interval)
+	}
+
+	return errors.Join(errs...)
+}
+
+func checkPositive(check func(bool, string, ...any), name string, d Duration) {
+	check(d > 0, "%s must be a positive duration, got %s", name, time.Duration(d))
+}
+
+func checkQuota(check func(bool, string, ...any), name string, quota RateLimitQuota) {
+	check(quota.Requests > 0, "%s.requests must be positive", name)
+	checkPositive(check, name+".window", quota.Window)
+}
+
+func hasScheme(rawURL string, schemes ...string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
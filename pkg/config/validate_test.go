@@ -0,0 +1,322 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	cfg := defaults()
+	cfg.Database.URI = "postgres://songs:secret@db:5432/songs?sslmode=disable"
+	cfg.Credentials.ApiKey = "0123456789abcdef"
+	cfg.External.ExtApiUrl = "http://music-info:8080"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string // подстрока ошибки; пусто — конфигурация корректна
+	}{
+		{name: "defaults with required values", modify: func(*Config) {}},
+
+		// database
+		{name: "database uri missing", modify: func(c *Config) { c.Database.URI = "" }, want: "database.uri is required"},
+		{name: "database uri postgresql scheme", modify: func(c *Config) { c.Database.URI = "postgresql://db/songs" }},
+		{name: "database uri mysql scheme", modify: func(c *Config) { c.Database.URI = "mysql://db/songs" }, want: "database.uri must be a postgres://"},
+		{name: "database uri keyword dsn", modify: func(c *Config) { c.Database.URI = "host=db dbname=songs" }, want: "database.uri must be a postgres://"},
+		{name: "database max_conns zero", modify: func(c *Config) { c.Database.MaxConns = 0 }, want: "database.max_conns must be positive"},
+		{name: "database min_conns above max", modify: func(c *Config) { c.Database.MinConns = c.Database.MaxConns + 1 }, want: "database.min_conns"},
+		{name: "database min_conns negative", modify: func(c *Config) { c.Database.MinConns = -1 }, want: "database.min_conns"},
+		{name: "database max_conn_lifetime", modify: func(c *Config) { c.Database.MaxConnLifetime = 0 }, want: "database.max_conn_lifetime"},
+		{name: "database max_conn_idle_time", modify: func(c *Config) { c.Database.MaxConnIdleTime = 0 }, want: "database.max_conn_idle_time"},
+		{name: "database health_check_period", modify: func(c *Config) { c.Database.HealthCheckPeriod = 0 }, want: "database.health_check_period"},
+		{name: "database reconnect_interval", modify: func(c *Config) { c.Database.ReconnectInterval = 0 }, want: "database.reconnect_interval"},
+		{name: "database connect_timeout", modify: func(c *Config) { c.Database.ConnectTimeout = Duration(-time.Second) }, want: "database.connect_timeout"},
+
+		// server
+		{name: "server url not http", modify: func(c *Config) { c.Server.ServerUrl = "ftp://localhost" }, want: "server.server_url"},
+		{name: "server url empty", modify: func(c *Config) { c.Server.ServerUrl = "" }},
+		{name: "server port not a number", modify: func(c *Config) { c.Server.Port = "http" }, want: "server.port"},
+		{name: "server port out of range", modify: func(c *Config) { c.Server.Port = "70000" }, want: "server.port"},
+		{name: "server read_timeout", modify: func(c *Config) { c.Server.ReadTimeout = 0 }, want: "server.read_timeout"},
+		{name: "server write_timeout", modify: func(c *Config) { c.Server.WriteTimeout = 0 }, want: "server.write_timeout"},
+		{name: "server idle_timeout", modify: func(c *Config) { c.Server.IdleTimeout = 0 }, want: "server.idle_timeout"},
+		{name: "server shutdown_timeout", modify: func(c *Config) { c.Server.ShutdownTimeout = 0 }, want: "server.shutdown_timeout"},
+		{name: "server drain_delay negative", modify: func(c *Config) { c.Server.DrainDelay = Duration(-time.Second) }, want: "server.drain_delay"},
+		{name: "server max_header_bytes", modify: func(c *Config) { c.Server.MaxHeaderBytes = 0 }, want: "server.max_header_bytes"},
+		{name: "server request_timeout", modify: func(c *Config) { c.Server.RequestTimeout = 0 }, want: "server.request_timeout must be a positive"},
+		{name: "server request_timeout not below write_timeout", modify: func(c *Config) { c.Server.RequestTimeout = c.Server.WriteTimeout }, want: "server.request_timeout must be less than server.write_timeout"},
+		{
+			name: "route timeout",
+			modify: func(c *Config) {
+				c.Server.RouteTimeouts = map[string]Duration{"GET /api/v1/songs": Duration(time.Second)}
+			},
+		},
+		{
+			name:   "route timeout key without method",
+			modify: func(c *Config) { c.Server.RouteTimeouts = map[string]Duration{"/api/v1/songs": Duration(time.Second)} },
+			want:   "server.route_timeouts: key",
+		},
+		{
+			name: "route timeout lowercase method",
+			modify: func(c *Config) {
+				c.Server.RouteTimeouts = map[string]Duration{"get /api/v1/songs": Duration(time.Second)}
+			},
+			want: "server.route_timeouts: key",
+		},
+		{
+			name:   "route timeout not positive",
+			modify: func(c *Config) { c.Server.RouteTimeouts = map[string]Duration{"GET /api/v1/songs": 0} },
+			want:   "server.route_timeouts.GET /api/v1/songs must be a positive",
+		},
+		{
+			name: "route timeout not below write_timeout",
+			modify: func(c *Config) {
+				c.Server.RouteTimeouts = map[string]Duration{"GET /api/v1/songs": c.Server.WriteTimeout}
+			},
+			want: "must be less than server.write_timeout",
+		},
+
+		// credentials
+		{name: "api key missing", modify: func(c *Config) { c.Credentials.ApiKey = "" }, want: "credentials.api_key is required"},
+		{name: "api key too short", modify: func(c *Config) { c.Credentials.ApiKey = "short" }, want: "credentials.api_key must be at least"},
+		{
+			name:   "jwt with jwks url",
+			modify: func(c *Config) { c.Credentials.JWT.Enabled = true; c.Credentials.JWT.JWKSURL = "https://idp/jwks" },
+		},
+		{
+			name:   "jwt without key source",
+			modify: func(c *Config) { c.Credentials.JWT.Enabled = true },
+			want:   "exactly one of jwks_url and jwks_file",
+		},
+		{
+			name: "jwt with both key sources",
+			modify: func(c *Config) {
+				c.Credentials.JWT.Enabled = true
+				c.Credentials.JWT.JWKSURL = "https://idp/jwks"
+				c.Credentials.JWT.JWKSFile = "jwks.json"
+			},
+			want: "exactly one of jwks_url and jwks_file",
+		},
+		{
+			name:   "jwt jwks url not http",
+			modify: func(c *Config) { c.Credentials.JWT.Enabled = true; c.Credentials.JWT.JWKSURL = "file:///jwks.json" },
+			want:   "credentials.jwt.jwks_url must be an http(s) URL",
+		},
+		{
+			name: "jwt refresh interval",
+			modify: func(c *Config) {
+				c.Credentials.JWT.Enabled = true
+				c.Credentials.JWT.JWKSFile = "jwks.json"
+				c.Credentials.JWT.RefreshInterval = 0
+			},
+			want: "credentials.jwt.refresh_interval",
+		},
+		{
+			name: "jwt scope claim",
+			modify: func(c *Config) {
+				c.Credentials.JWT.Enabled = true
+				c.Credentials.JWT.JWKSFile = "jwks.json"
+				c.Credentials.JWT.ScopeClaim = ""
+			},
+			want: "credentials.jwt.scope_claim",
+		},
+		{
+			name: "jwt user id claim",
+			modify: func(c *Config) {
+				c.Credentials.JWT.Enabled = true
+				c.Credentials.JWT.JWKSFile = "jwks.json"
+				c.Credentials.JWT.UserIDClaim = ""
+			},
+			want: "credentials.jwt.user_id_claim",
+		},
+		{name: "jwt settings ignored when disabled", modify: func(c *Config) { c.Credentials.JWT.ScopeClaim = "" }},
+		{
+			name:   "user tokens",
+			modify: func(c *Config) { c.Credentials.UserTokens.SigningKey = strings.Repeat("k", minSigningKeyLength) },
+		},
+		{
+			name:   "user tokens signing key too short",
+			modify: func(c *Config) { c.Credentials.UserTokens.SigningKey = "short" },
+			want:   "credentials.user_tokens.signing_key",
+		},
+		{
+			name: "user tokens ttl",
+			modify: func(c *Config) {
+				c.Credentials.UserTokens.SigningKey = strings.Repeat("k", minSigningKeyLength)
+				c.Credentials.UserTokens.TTL = 0
+			},
+			want: "credentials.user_tokens.ttl",
+		},
+		{
+			name: "user tokens issuer",
+			modify: func(c *Config) {
+				c.Credentials.UserTokens.SigningKey = strings.Repeat("k", minSigningKeyLength)
+				c.Credentials.UserTokens.Issuer = ""
+			},
+			want: "credentials.user_tokens.issuer",
+		},
+		{
+			name: "hmac client",
+			modify: func(c *Config) {
+				c.Credentials.HMAC.Clients = []HMACClient{{ID: "a", Secret: "s", Scopes: []string{"songs:read"}}}
+			},
+		},
+		{
+			name: "hmac client without id",
+			modify: func(c *Config) {
+				c.Credentials.HMAC.Clients = []HMACClient{{Secret: "s", Scopes: []string{"songs:read"}}}
+			},
+			want: "credentials.hmac.clients[0].id is required",
+		},
+		{
+			name:   "hmac client without secret",
+			modify: func(c *Config) { c.Credentials.HMAC.Clients = []HMACClient{{ID: "a", Scopes: []string{"songs:read"}}} },
+			want:   "credentials.hmac.clients[0].secret is required",
+		},
+		{
+			name:   "hmac client without scopes",
+			modify: func(c *Config) { c.Credentials.HMAC.Clients = []HMACClient{{ID: "a", Secret: "s"}} },
+			want:   "credentials.hmac.clients[0].scopes",
+		},
+		{
+			name: "hmac duplicate client",
+			modify: func(c *Config) {
+				client := HMACClient{ID: "a", Secret: "s", Scopes: []string{"songs:read"}}
+				c.Credentials.HMAC.Clients = []HMACClient{client, client}
+			},
+			want: `credentials.hmac.clients[1]: duplicate id "a"`,
+		},
+		{
+			name: "hmac max skew",
+			modify: func(c *Config) {
+				c.Credentials.HMAC.Clients = []HMACClient{{ID: "a", Secret: "s", Scopes: []string{"songs:read"}}}
+				c.Credentials.HMAC.MaxSkew = 0
+			},
+			want: "credentials.hmac.max_skew",
+		},
+		{
+			name: "hmac nonce cache size",
+			modify: func(c *Config) {
+				c.Credentials.HMAC.Clients = []HMACClient{{ID: "a", Secret: "s", Scopes: []string{"songs:read"}}}
+				c.Credentials.HMAC.NonceCacheSize = 0
+			},
+			want: "credentials.hmac.nonce_cache_size",
+		},
+
+		// external
+		{name: "external url missing", modify: func(c *Config) { c.External.ExtApiUrl = "" }, want: "external.ext_api_url is required"},
+		{name: "external url without host", modify: func(c *Config) { c.External.ExtApiUrl = "http://" }, want: "external.ext_api_url must be an http(s) URL"},
+		{name: "external timeout", modify: func(c *Config) { c.External.Timeout = 0 }, want: "external.timeout"},
+		{name: "external rate limit", modify: func(c *Config) { c.External.RateLimit = 0 }, want: "external.rate_limit"},
+		{name: "external burst", modify: func(c *Config) { c.External.Burst = 0 }, want: "external.burst"},
+		{name: "external max concurrency", modify: func(c *Config) { c.External.MaxConcurrency = 0 }, want: "external.max_concurrency"},
+		{name: "external circuit failures", modify: func(c *Config) { c.External.CircuitFailures = 0 }, want: "external.circuit_failures"},
+		{name: "external circuit cooldown", modify: func(c *Config) { c.External.CircuitCooldown = 0 }, want: "external.circuit_cooldown"},
+
+		// enrichment
+		{name: "enrichment settings ignored when disabled", modify: func(c *Config) { c.Enrichment.BatchSize = 0 }},
+		{name: "enrichment interval", modify: func(c *Config) { c.Enrichment.Enabled = true; c.Enrichment.Interval = 0 }, want: "enrichment.interval"},
+		{name: "enrichment max age", modify: func(c *Config) { c.Enrichment.Enabled = true; c.Enrichment.MaxAge = 0 }, want: "enrichment.max_age"},
+		{name: "enrichment batch size", modify: func(c *Config) { c.Enrichment.Enabled = true; c.Enrichment.BatchSize = 0 }, want: "enrichment.batch_size"},
+
+		// rate_limit
+		{name: "rate limit settings ignored when disabled", modify: func(c *Config) { c.RateLimit.Store = "redis" }},
+		{name: "rate limit store", modify: func(c *Config) { c.RateLimit.Enabled = true; c.RateLimit.Store = "redis" }, want: "rate_limit.store"},
+		{
+			name:   "rate limit default requests",
+			modify: func(c *Config) { c.RateLimit.Enabled = true; c.RateLimit.Default.Requests = 0 },
+			want:   "rate_limit.default.requests",
+		},
+		{
+			name:   "rate limit default window",
+			modify: func(c *Config) { c.RateLimit.Enabled = true; c.RateLimit.Default.Window = 0 },
+			want:   "rate_limit.default.window",
+		},
+		{
+			name: "rate limit scope quota",
+			modify: func(c *Config) {
+				c.RateLimit.Enabled = true
+				c.RateLimit.Scopes = map[string]RateLimitQuota{"songs:write": {Requests: 0, Window: Duration(time.Minute)}}
+			},
+			want: "rate_limit.scopes.songs:write.requests",
+		},
+
+		// tracing
+		{name: "tracing otlp", modify: func(c *Config) { c.Tracing.Exporter = "otlp"; c.Tracing.Endpoint = "http://collector:4318" }},
+		{name: "tracing otlp endpoint", modify: func(c *Config) { c.Tracing.Exporter = "otlp"; c.Tracing.Endpoint = "collector:4318" }, want: "tracing.endpoint"},
+		{name: "tracing unknown exporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, want: "tracing.exporter"},
+		{name: "tracing service name", modify: func(c *Config) { c.Tracing.ServiceName = "" }, want: "tracing.service_name"},
+		{name: "tracing sample ratio", modify: func(c *Config) { c.Tracing.SampleRatio = 1.5 }, want: "tracing.sample_ratio"},
+
+		// log
+		{name: "log level", modify: func(c *Config) { c.Log.Level = "verbose" }, want: "log.level"},
+		{name: "log format", modify: func(c *Config) { c.Log.Format = "xml" }, want: "log.format"},
+		{name: "log package level", modify: func(c *Config) { c.Log.Packages = map[string]string{"persistence": "debug"} }},
+		{name: "log unknown package level", modify: func(c *Config) { c.Log.Packages = map[string]string{"persistence": "loud"} }, want: "log.packages.persistence"},
+		{name: "log empty package name", modify: func(c *Config) { c.Log.Packages = map[string]string{"": "debug"} }, want: "log.packages: package name"},
+		{name: "log sampling initial", modify: func(c *Config) { c.Log.Sampling.Enabled = true; c.Log.Sampling.Initial = 0 }, want: "log.sampling.initial"},
+		{
+			name:   "log sampling thereafter",
+			modify: func(c *Config) { c.Log.Sampling.Enabled = true; c.Log.Sampling.Thereafter = -1 },
+			want:   "log.sampling.thereafter",
+		},
+		{name: "log sampling interval", modify: func(c *Config) { c.Log.Sampling.Enabled = true; c.Log.Sampling.Interval = 0 }, want: "log.sampling.interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.URI = ""
+	cfg.Server.Port = "0"
+	cfg.Log.Format = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"database.uri", "server.port", "log.format"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+// config print не проверяет конфигурацию, поэтому строка подключения, которую Validate отклоняет,
+// всё равно должна быть замаскирована.
+func TestPrintRedactsInvalidDatabaseURI(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.URI = "host=db user=songs password=hunter2 dbname=songs"
+	if cfg.Validate() == nil {
+		t.Fatal("keyword dsn must be rejected by Validate")
+	}
+
+	var out strings.Builder
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("printed config leaks the password:\n%s", out.String())
+	}
+}
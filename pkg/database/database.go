@@ -4,107 +4,298 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"runtime"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type DB struct {
-	Conn *pgxpool.Pool
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+	pingTimeout    = 5 * time.Second
+)
+
+// ErrClosed возвращается запросами после Close: пула уже нет, а новый не будет открыт.
+var ErrClosed = errors.New("database is closed")
+
+// Options задаёт параметры пула соединений и проверки связи с базой.
+type Options struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	ReconnectInterval time.Duration
+	// ConnectTimeout ограничивает ожидание доступности базы при старте.
+	ConnectTimeout time.Duration
+
+	// Migrations — SQL-миграции схемы. При AutoMigrate они применяются при старте,
+	// иначе только сверяется версия схемы.
+	Migrations  fs.FS
+	AutoMigrate bool
 }
 
-func (d *DB) Close() error {
-	d.Conn.Close()
-	return nil
+// Health — состояние соединения с базой по последней проверке.
+type Health struct {
+	Healthy   bool
+	CheckedAt time.Time
+	// Since — момент последней смены состояния.
+	Since     time.Time
+	LastError error
+}
+
+// DB владеет пулом соединений и следит за ним: при потере связи создаётся новый пул,
+// который атомарно подменяет старый, поэтому репозитории всегда получают рабочий пул через Pool().
+type DB struct {
+	uri    string
+	opts   Options
+	logger *slog.Logger
+
+	pool atomic.Pointer[pgxpool.Pool]
+	// schemaVersion — версия схемы, на которую рассчитан сервис.
+	schemaVersion uint
+
+	mu     sync.RWMutex
+	health Health
 }
 
-func Init(DBURI string) (*DB, error) {
-	var result DB
-	var err error
+// Init дожидается доступности базы, повторяя попытки с экспоненциальной задержкой,
+// применяет миграции, если включён AutoMigrate, и возвращает DB с открытым пулом.
+func Init(ctx context.Context, DBURI string, opts Options, logger *slog.Logger) (*DB, error) {
+	db := &DB{
+		uri:    DBURI,
+		opts:   opts,
+		logger: logger.With("component", "Database"),
+	}
 
-	result.Conn, err = openPGConnection(DBURI)
+	if opts.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.ConnectTimeout)
+		defer cancel()
+	}
+
+	pool, err := db.connectWithRetry(ctx)
 	if err != nil {
 		return nil, err
 	}
-	result.Conn.Config().MaxConns = int32(runtime.NumCPU())
 
-	time.Sleep(5 * time.Second)
-	if err = testConnection(result.Conn); err != nil {
-		return nil, err
+	db.schemaVersion, err = db.prepareSchema()
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("migrations error: %w", err)
 	}
 
-	time.Sleep(5 * time.Second)
-	go connectionWorker(result.Conn, DBURI)
+	db.pool.Store(pool)
+	db.setHealth(nil)
 
-	return &result, nil
+	return db, nil
 }
 
-func openPGConnection(dbURI string) (*pgxpool.Pool, error) {
-	if err := migrateUp(dbURI); err != nil {
-		return nil, fmt.Errorf("migrations error: %w", err)
+// Pool возвращает текущий пул соединений.
+func (d *DB) Pool() *pgxpool.Pool {
+	return d.pool.Load()
+}
+
+// Health возвращает состояние соединения по последней проверке.
+func (d *DB) Health() Health {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.health
+}
+
+// Ping проверяет связь с базой через текущий пул.
+func (d *DB) Ping(ctx context.Context) error {
+	return ping(ctx, d.Pool())
+}
+
+// SchemaVersion возвращает версию схемы, на которую рассчитан сервис: последнюю из его миграций.
+func (d *DB) SchemaVersion() uint {
+	return d.schemaVersion
+}
+
+// MigrationVersion читает текущую версию схемы из таблицы schema_migrations.
+func (d *DB) MigrationVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	pool := d.Pool()
+	if pool == nil {
+		return 0, false, ErrClosed
 	}
-	conn, err := pgxpool.New(context.Background(), dbURI)
+
+	var v int64
+	err = pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&v, &dirty)
 	if err != nil {
-		return nil, err
+		return 0, false, err
 	}
-	return conn, nil
+	return uint(v), dirty, nil
 }
 
-func testConnection(db *pgxpool.Pool) error {
-	err := db.Ping(context.Background())
-	errPing := errors.New("can't ping postgres")
-	if err != nil {
-		return errors.Join(err, errPing)
+func (d *DB) Close() error {
+	if pool := d.pool.Swap(nil); pool != nil {
+		pool.Close()
 	}
 	return nil
 }
 
-func connectionWorker(conn *pgxpool.Pool, dbURI string) {
-	ticker := time.NewTicker(5 * time.Second)
+// Supervise проверяет соединение раз в ReconnectInterval, пока не отменён ctx.
+// Если база не отвечает, открывается новый пул и подменяет старый; неудачная попытка
+// повторяется на следующей проверке.
+func (d *DB) Supervise(ctx context.Context) {
+	interval := d.opts.ReconnectInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			if err := testConnection(conn); err != nil {
-				fmt.Printf("lost connection to database: %v\n", err)
-				var newConn *pgxpool.Pool
-				newConn, err = openPGConnection(dbURI)
+			err := ping(ctx, d.Pool())
+			if err == nil {
+				d.setHealth(nil)
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			d.setHealth(err)
+			d.logger.Warn("lost connection to database, reconnecting", "error", err)
+
+			pool, err := openPool(ctx, d.uri, d.opts)
+			if err == nil {
+				err = ping(ctx, pool)
 				if err != nil {
-					fmt.Errorf("failed to reconnect to PostgreSQL: %v. Exiting application", err)
-					return
+					pool.Close()
 				}
-				conn = newConn
-				fmt.Println("successfully reconnected to PostgreSQL.")
 			}
+			if err != nil {
+				d.setHealth(err)
+				d.logger.Error("failed to reconnect to database", "error", err, "retryIn", interval)
+				continue
+			}
+
+			if old := d.pool.Swap(pool); old != nil {
+				// Close ждёт возврата занятых соединений, поэтому не блокирует проверку.
+				go old.Close()
+			}
+			d.setHealth(nil)
+			d.logger.Info("reconnected to database")
 		}
 	}
 }
 
-func migrateUp(dbURI string) error {
+func (d *DB) setHealth(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	healthy := err == nil
+	if healthy != d.health.Healthy || d.health.Since.IsZero() {
+		d.health.Since = now
+	}
+	d.health.Healthy = healthy
+	d.health.CheckedAt = now
+	d.health.LastError = err
+}
+
+func (d *DB) connectWithRetry(ctx context.Context) (*pgxpool.Pool, error) {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		pool, err := openPool(ctx, d.uri, d.opts)
+		if err == nil {
+			if err = ping(ctx, pool); err == nil {
+				d.logger.Info("connected to database", "attempt", attempt)
+				return pool, nil
+			}
+			pool.Close()
+		}
 
-	m, err := migrate.New("file://migrations/", dbURI)
+		d.logger.Warn("database is not available yet", "attempt", attempt, "retryIn", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for database: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
 
+func openPool(ctx context.Context, dbURI string, opts Options) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dbURI)
 	if err != nil {
-		return fmt.Errorf("new error: %w", err)
+		return nil, err
+	}
+	if opts.MaxConns > 0 {
+		poolConfig.MaxConns = opts.MaxConns
+	}
+	poolConfig.MinConns = opts.MinConns
+	if opts.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = opts.HealthCheckPeriod
 	}
 
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("up migrations error: %w", err)
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+func ping(ctx context.Context, pool *pgxpool.Pool) error {
+	if pool == nil {
+		return ErrClosed
 	}
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
 
-	sourceErr, dbErr := m.Close()
-	if sourceErr != nil {
-		return fmt.Errorf("close source error: %w", sourceErr)
+	if err := pool.Ping(ctx); err != nil {
+		return errors.Join(err, errors.New("can't ping postgres"))
 	}
+	return nil
+}
 
-	if dbErr != nil {
-		return fmt.Errorf("close db error: %w", dbErr)
+// prepareSchema применяет миграции (если включён AutoMigrate) и возвращает версию последней из них.
+// Без AutoMigrate отставание схемы только логируется: его покажет проверка готовности.
+func (d *DB) prepareSchema() (uint, error) {
+	m, err := NewMigrator(d.opts.Migrations, d.uri)
+	if err != nil {
+		return 0, err
 	}
+	defer func() {
+		if err := m.Close(); err != nil {
+			d.logger.Warn("error closing migrator", "error", err)
+		}
+	}()
 
-	return nil
+	latest, err := m.Latest()
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+
+	if d.opts.AutoMigrate {
+		if err := m.Up(); err != nil && !errors.Is(err, ErrNoChange) {
+			return 0, fmt.Errorf("up migrations error: %w", err)
+		}
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return 0, fmt.Errorf("migration version error: %w", err)
+	}
+	switch {
+	case dirty:
+		d.logger.Error("database schema is dirty, fix it and run migrate force", "version", version)
+	case version < latest:
+		d.logger.Warn("database schema is behind, run migrate up", "version", version, "expected", latest)
+	default:
+		d.logger.Info("database schema is up to date", "version", version)
+	}
+
+	return latest, nil
 }
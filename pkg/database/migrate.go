@@ -0,0 +1,102 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrNoChange означает, что схема уже в нужной версии.
+var ErrNoChange = migrate.ErrNoChange
+
+// Migrator применяет миграции из fs.FS (например, встроенных через embed) к базе dbURI.
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+// NewMigrator открывает базу по dbURI — URL вида postgres://, как того требует config.Validate.
+func NewMigrator(migrations fs.FS, dbURI string) (*Migrator, error) {
+	parsed, err := url.Parse(dbURI)
+	if err != nil || parsed.Scheme == "" {
+		return nil, errors.New("new migrator: database uri must be a postgres:// URL")
+	}
+
+	src, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dbURI)
+	if err != nil {
+		// migrate включает адрес базы в текст ошибки, пароль в нём не должен попасть в лог.
+		return nil, fmt.Errorf("new migrator: %s", strings.ReplaceAll(err.Error(), dbURI, parsed.Redacted()))
+	}
+	// Отдельный экземпляр источника для Latest: migrate владеет своим и закрывает его в Close.
+	latest, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	return &Migrator{m: m, source: latest}, nil
+}
+
+// Up применяет все новые миграции.
+func (m *Migrator) Up() error {
+	return m.m.Up()
+}
+
+// Down откатывает n последних миграций.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return errors.New("number of migrations to roll back must be positive")
+	}
+	return m.m.Steps(-n)
+}
+
+// Goto переводит схему в версию version, применяя или откатывая миграции.
+func (m *Migrator) Goto(version uint) error {
+	return m.m.Migrate(version)
+}
+
+// Force записывает версию без выполнения миграций, чтобы снять флаг dirty после ручного исправления.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Version возвращает текущую версию схемы; 0, если миграции ещё не применялись.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Latest возвращает номер последней миграции в источнике.
+func (m *Migrator) Latest() (uint, error) {
+	version, err := m.source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := m.source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr, m.source.Close())
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier — общие методы пула и транзакции, через которые репозитории выполняют запросы.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// closedQuerier отвечает ErrClosed на любой запрос, чтобы репозитории, работающие во время
+// остановки сервиса, получали ошибку, а не обращались к nil-пулу.
+type closedQuerier struct{}
+
+func (closedQuerier) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, ErrClosed
+}
+
+func (closedQuerier) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, ErrClosed
+}
+
+func (closedQuerier) QueryRow(context.Context, string, ...any) pgx.Row {
+	return closedRow{}
+}
+
+type closedRow struct{}
+
+func (closedRow) Scan(...any) error {
+	return ErrClosed
+}
+
+// beginner — то, что нужно InTx от пула; в тестах его заменяет подделка.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Conn возвращает транзакцию из ctx, если запрос выполняется внутри InTx, иначе текущий пул.
+// Репозитории получают соединение только через Conn, поэтому не знают, участвуют ли они в транзакции.
+func (d *DB) Conn(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	if pool := d.Pool(); pool != nil {
+		return pool
+	}
+	return closedQuerier{}
+}
+
+// InTx выполняет fn в транзакции: она фиксируется, если fn вернул nil, и откатывается при ошибке или панике.
+// Вложенный вызов InTx присоединяется к внешней транзакции, поэтому методы сервисов можно комбинировать.
+// В fn не стоит обращаться к внешним системам: транзакция держит соединение и блокировки до своего конца.
+func (d *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	pool := d.Pool()
+	if pool == nil {
+		return ErrClosed
+	}
+	return inTx(ctx, pool, fn)
+}
+
+func inTx(ctx context.Context, db beginner, fn func(ctx context.Context) error) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+		if err != nil {
+			// Откат выполняется и после отмены ctx, иначе соединение вернётся в пул с открытой транзакцией.
+			if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				err = errors.Join(err, fmt.Errorf("rollback transaction: %w", rbErr))
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeTx запоминает, чем закончилась транзакция; остальные методы pgx.Tx тестам не нужны.
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	if t.commitErr != nil {
+		return t.commitErr
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	if t.committed || t.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	t.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	tx     *fakeTx
+	begins int
+}
+
+func (b *fakeBeginner) Begin(context.Context) (pgx.Tx, error) {
+	b.begins++
+	return b.tx, nil
+}
+
+func TestInTx(t *testing.T) {
+	errFn := errors.New("fn failed")
+	errCommit := errors.New("commit failed")
+
+	tests := []struct {
+		name         string
+		fn           func(ctx context.Context) error
+		commitErr    error
+		wantErr      error
+		wantCommit   bool
+		wantRollback bool
+	}{
+		{
+			name:       "commits when fn succeeds",
+			fn:         func(context.Context) error { return nil },
+			wantCommit: true,
+		},
+		{
+			name:         "rolls back when fn fails",
+			fn:           func(context.Context) error { return errFn },
+			wantErr:      errFn,
+			wantRollback: true,
+		},
+		{
+			name:         "rolls back when commit fails",
+			fn:           func(context.Context) error { return nil },
+			commitErr:    errCommit,
+			wantErr:      errCommit,
+			wantRollback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &fakeBeginner{tx: &fakeTx{commitErr: tt.commitErr}}
+
+			err := inTx(context.Background(), b, tt.fn)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if b.tx.committed != tt.wantCommit {
+				t.Errorf("committed = %v, want %v", b.tx.committed, tt.wantCommit)
+			}
+			if b.tx.rolledBack != tt.wantRollback {
+				t.Errorf("rolledBack = %v, want %v", b.tx.rolledBack, tt.wantRollback)
+			}
+		})
+	}
+}
+
+func TestInTxRollsBackOnPanic(t *testing.T) {
+	b := &fakeBeginner{tx: &fakeTx{}}
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Fatalf("panic must be re-raised, got %v", p)
+		}
+		if !b.tx.rolledBack || b.tx.committed {
+			t.Errorf("transaction must be rolled back, got committed=%v rolledBack=%v", b.tx.committed, b.tx.rolledBack)
+		}
+	}()
+
+	_ = inTx(context.Background(), b, func(context.Context) error { panic("boom") })
+}
+
+func TestNestedInTxJoinsOuterTransaction(t *testing.T) {
+	d := &DB{}
+	b := &fakeBeginner{tx: &fakeTx{}}
+
+	err := inTx(context.Background(), b, func(ctx context.Context) error {
+		return d.InTx(ctx, func(ctx context.Context) error {
+			if q := d.Conn(ctx); q != pgx.Tx(b.tx) {
+				t.Errorf("Conn inside nested InTx = %T, want the outer transaction", q)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.begins != 1 {
+		t.Errorf("began %d transactions, want 1", b.begins)
+	}
+	if !b.tx.committed {
+		t.Error("outer transaction must be committed")
+	}
+}
+
+func TestClosedDatabase(t *testing.T) {
+	d := &DB{}
+	ctx := context.Background()
+
+	if err := d.InTx(ctx, func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("InTx: got %v, want ErrClosed", err)
+	}
+
+	q := d.Conn(ctx)
+	if _, err := q.Exec(ctx, "SELECT 1"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Exec: got %v, want ErrClosed", err)
+	}
+	if _, err := q.Query(ctx, "SELECT 1"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Query: got %v, want ErrClosed", err)
+	}
+	var n int
+	if err := q.QueryRow(ctx, "SELECT 1").Scan(&n); !errors.Is(err, ErrClosed) {
+		t.Errorf("QueryRow: got %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,52 @@
+package httpinfo
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Unmatched подставляется вместо маршрута, если запрос не сопоставлен ни с одним шаблоном.
+// Сырой путь не используется, чтобы число имён спанов и рядов метрик не зависело от запросов.
+const Unmatched = "unmatched"
+
+// Route возвращает шаблон маршрута gorilla/mux, например /api/v1/songs/{id}, или Unmatched.
+func Route(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return Unmatched
+}
+
+// Recorder запоминает код ответа и число записанных байт.
+type Recorder struct {
+	http.ResponseWriter
+	Status      int
+	Bytes       int
+	wroteHeader bool
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.Status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.Bytes += n
+	return n, err
+}
+
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,288 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options задаёт формат и уровни логирования.
+type Options struct {
+	Format    string
+	AddSource bool
+	Level     slog.Level
+	// Packages переопределяет Level для пакетов. Ключ — имя пакета (persistence)
+	// или полный путь импорта (effictiveMobile/internal/infrastrtucture/persistence).
+	Packages map[string]slog.Level
+	Sampling Sampling
+}
+
+// Sampling ограничивает повторяющиеся записи уровня error: в каждом окне Interval
+// запись с тем же сообщением из того же пакета пишется первые Initial раз,
+// а затем только каждая Thereafter-я. Нулевой Initial отключает ограничение.
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Interval   time.Duration
+}
+
+// Override — временный уровень, выставленный через Levels.SetTemporary.
+type Override struct {
+	// Package пустой для общего уровня.
+	Package   string
+	Level     slog.Level
+	ExpiresAt time.Time
+}
+
+// Levels управляет уровнями логгера, созданного New: их можно перечитать из конфигурации
+// или временно изменить, не пересоздавая логгер.
+type Levels struct {
+	configured atomic.Pointer[Options]
+	effective  atomic.Pointer[levelSet]
+
+	mu        sync.Mutex
+	overrides map[string]*override
+
+	sampler *sampler
+	// packages кэширует пакет по адресу вызова, чтобы не разбирать стек на каждую запись.
+	packages sync.Map
+}
+
+type override struct {
+	Override
+	timer *time.Timer
+}
+
+// levelSet — итоговые уровни с учётом временных переопределений.
+type levelSet struct {
+	base     slog.Level
+	packages map[string]slog.Level
+	// min — самый подробный из уровней, по нему Enabled отсекает записи до их построения.
+	min slog.Level
+}
+
+// New создаёт логгер, пишущий в w, и управление его уровнями.
+func New(w io.Writer, opts Options) (*slog.Logger, *Levels) {
+	levels := &Levels{
+		overrides: make(map[string]*override),
+		sampler:   &sampler{counts: make(map[string]int)},
+	}
+	levels.Configure(opts)
+
+	// Уровень проверяет handler; внутреннему пропускаем всё.
+	handlerOpts := &slog.HandlerOptions{AddSource: opts.AddSource, Level: slog.Level(-1 << 10)}
+	var inner slog.Handler
+	if opts.Format == FormatText {
+		inner = slog.NewTextHandler(w, handlerOpts)
+	} else {
+		inner = slog.NewJSONHandler(w, handlerOpts)
+	}
+
+	return slog.New(&handler{inner: inner, levels: levels}), levels
+}
+
+// Configure применяет уровни и параметры сэмплирования из новой конфигурации.
+// Формат и AddSource задаются только при создании логгера. Временные уровни сохраняются до истечения срока.
+func (l *Levels) Configure(opts Options) {
+	l.configured.Store(&opts)
+	l.sampler.configure(opts.Sampling)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recompute()
+}
+
+// SetTemporary выставляет уровень для пакета (или общий, если pkg пустой) на время d.
+// По истечении срока возвращается уровень из конфигурации.
+func (l *Levels) SetTemporary(pkg string, level slog.Level, d time.Duration) Override {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if previous, ok := l.overrides[pkg]; ok {
+		previous.timer.Stop()
+	}
+
+	o := &override{Override: Override{Package: pkg, Level: level, ExpiresAt: time.Now().Add(d)}}
+	o.timer = time.AfterFunc(d, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.overrides[pkg] == o {
+			delete(l.overrides, pkg)
+			l.recompute()
+		}
+	})
+	l.overrides[pkg] = o
+	l.recompute()
+
+	return o.Override
+}
+
+// Reset отменяет временный уровень пакета (или общий, если pkg пустой).
+func (l *Levels) Reset(pkg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if o, ok := l.overrides[pkg]; ok {
+		o.timer.Stop()
+		delete(l.overrides, pkg)
+		l.recompute()
+	}
+}
+
+// Snapshot возвращает действующие уровни: общий, по пакетам и список временных переопределений.
+func (l *Levels) Snapshot() (base slog.Level, packages map[string]slog.Level, overrides []Override) {
+	set := l.effective.Load()
+	packages = make(map[string]slog.Level, len(set.packages))
+	for pkg, level := range set.packages {
+		packages[pkg] = level
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, o := range l.overrides {
+		overrides = append(overrides, o.Override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Package < overrides[j].Package })
+
+	return set.base, packages, overrides
+}
+
+// recompute вызывается под l.mu.
+func (l *Levels) recompute() {
+	opts := l.configured.Load()
+	set := &levelSet{base: opts.Level, packages: make(map[string]slog.Level, len(opts.Packages))}
+	for pkg, level := range opts.Packages {
+		set.packages[pkg] = level
+	}
+	for pkg, o := range l.overrides {
+		if pkg == "" {
+			set.base = o.Level
+		} else {
+			set.packages[pkg] = o.Level
+		}
+	}
+
+	set.min = set.base
+	for _, level := range set.packages {
+		set.min = min(set.min, level)
+	}
+	l.effective.Store(set)
+}
+
+func (s *levelSet) levelFor(pkgPath string) slog.Level {
+	if len(s.packages) == 0 {
+		return s.base
+	}
+	if level, ok := s.packages[pkgPath]; ok {
+		return level
+	}
+	if level, ok := s.packages[pkgPath[strings.LastIndex(pkgPath, "/")+1:]]; ok {
+		return level
+	}
+	return s.base
+}
+
+// packageOf возвращает путь импорта пакета функции, из которой сделана запись.
+func (l *Levels) packageOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if pkg, ok := l.packages.Load(pc); ok {
+		return pkg.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	// Имя функции вида effictiveMobile/internal/domain/service.(*SongServiceImpl).GetSongs.
+	name := frame.Function
+	slash := strings.LastIndex(name, "/")
+	pkg := name
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		pkg = name[:slash+1+dot]
+	}
+
+	l.packages.Store(pc, pkg)
+	return pkg
+}
+
+type handler struct {
+	inner  slog.Handler
+	levels *Levels
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.effective.Load().min
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	pkg := h.levels.packageOf(r.PC)
+	if r.Level < h.levels.effective.Load().levelFor(pkg) {
+		return nil
+	}
+	if r.Level >= slog.LevelError && !h.levels.sampler.allow(pkg+"\x00"+r.Message) {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{inner: h.inner.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{inner: h.inner.WithGroup(name), levels: h.levels}
+}
+
+type sampler struct {
+	mu          sync.Mutex
+	cfg         Sampling
+	windowStart time.Time
+	counts      map[string]int
+}
+
+func (s *sampler) configure(cfg Sampling) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.windowStart = time.Time{}
+}
+
+func (s *sampler) allow(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.Initial <= 0 {
+		return true
+	}
+	if now := time.Now(); now.Sub(s.windowStart) >= s.cfg.Interval {
+		s.windowStart = now
+		clear(s.counts)
+	}
+
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.cfg.Initial {
+		return true
+	}
+	return s.cfg.Thereafter > 0 && (n-s.cfg.Initial)%s.cfg.Thereafter == 0
+}
+
+// ParseLevel разбирает уровень вида debug, info, warn, error (допускаются смещения вроде debug+2).
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
@@ -0,0 +1,57 @@
+package requestctx
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+type loggerKey struct{}
+
+// logScope хранит логгер запроса. Атрибуты, которые становятся известны позже
+// (например, клиент после аутентификации), добавляются в тот же scope, поэтому
+// их видит и middleware, положивший логгер в контекст.
+type logScope struct {
+	state atomic.Pointer[logState]
+}
+
+type logState struct {
+	logger *slog.Logger
+	attrs  []any
+}
+
+// WithLogger кладёт в контекст логгер запроса: logger с атрибутами args.
+func WithLogger(ctx context.Context, logger *slog.Logger, args ...any) context.Context {
+	scope := &logScope{}
+	scope.state.Store(&logState{logger: logger.With(args...), attrs: args})
+	return context.WithValue(ctx, loggerKey{}, scope)
+}
+
+// AddLogAttrs дополняет логгер текущего запроса; вне HTTP-запроса ничего не делает.
+func AddLogAttrs(ctx context.Context, args ...any) {
+	scope, ok := ctx.Value(loggerKey{}).(*logScope)
+	if !ok {
+		return
+	}
+	state := scope.state.Load()
+	attrs := append(append([]any{}, state.attrs...), args...)
+	scope.state.Store(&logState{logger: state.logger.With(args...), attrs: attrs})
+}
+
+// Logger возвращает logger компонента с атрибутами текущего запроса.
+// Вне HTTP-запроса logger возвращается как есть; если logger nil, возвращается логгер запроса
+// или slog.Default().
+func Logger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	scope, ok := ctx.Value(loggerKey{}).(*logScope)
+	if !ok {
+		if logger == nil {
+			return slog.Default()
+		}
+		return logger
+	}
+	state := scope.state.Load()
+	if logger == nil {
+		return state.logger
+	}
+	return logger.With(state.attrs...)
+}